@@ -226,10 +226,29 @@ func (a *AggregateQuery) Filter(expression string) *AggregateQuery {
 	return a
 }
 
+// Serialize returns the FT.AGGREGATE arguments of the query.
+// It returns no arguments at all if the query cannot be serialized, e.g. because of an invalid filter
+// or query parameter, without telling why
+//
+// Deprecated: Please use Args() instead, which returns the error
 func (q AggregateQuery) Serialize() redis.Args {
+	args, _ := q.serialize()
+	return args
+}
+
+// Args returns the FT.AGGREGATE arguments of the query, or the error preventing its serialization
+func (q AggregateQuery) Args() (redis.Args, error) {
+	return q.serialize()
+}
+
+func (q AggregateQuery) serialize() (redis.Args, error) {
 	args := redis.Args{}
 	if q.Query != nil {
-		args = args.AddFlat(q.Query.serialize())
+		queryArgs, err := q.Query.aggregateArgs()
+		if err != nil {
+			return args, err
+		}
+		args = args.AddFlat(queryArgs)
 	} else {
 		args = args.Add("*")
 	}
@@ -257,7 +276,7 @@ func (q AggregateQuery) Serialize() redis.Args {
 		args = args.Add("LIMIT", q.Paging.Offset, q.Paging.Num)
 	}
 
-	return args
+	return args, nil
 }
 
 // Deprecated: Please use processAggReply() instead
@@ -0,0 +1,156 @@
+package redisearch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// AggregateIterator iterates over all the rows of an aggregation, reading them page by page through a cursor.
+// The connection is pinned for the whole iteration, since a cursor only exists on the server that created it.
+// Close must be called when the iteration is abandoned before its end, so that the cursor is deleted
+// on the server instead of lingering until its MAXIDLE timeout
+//
+//	it := c.AggregateIter(q)
+//	defer it.Close()
+//	for it.Next() {
+//		row := it.Row()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type AggregateIterator struct {
+	client   *Client
+	ctx      context.Context
+	query    AggregateQuery
+	conn     redis.Conn
+	cursorId int
+	started  bool
+	closed   bool
+	rows     []AggregateRow
+	pos      int
+	row      AggregateRow
+	err      error
+}
+
+// AggregateIter returns an iterator over all the rows of the aggregation q.
+// The query is run WITHCURSOR, with the COUNT and MAXIDLE of q.Cursor when set; q itself is not modified
+func (i *Client) AggregateIter(q *AggregateQuery) *AggregateIterator {
+	return i.AggregateIterContext(context.Background(), q)
+}
+
+// AggregateIterContext is the context-aware version of AggregateIter.
+// The context applies to every page read by the iterator
+func (i *Client) AggregateIterContext(ctx context.Context, q *AggregateQuery) *AggregateIterator {
+	cursor := NewCursor()
+	if q.Cursor != nil {
+		cursor.SetCount(q.Cursor.Count).SetMaxIdle(q.Cursor.MaxIdle)
+	}
+	it := &AggregateIterator{client: i, ctx: ctx, query: *q}
+	it.query.SetCursor(cursor)
+	return it
+}
+
+// Next advances the iterator to the next row, reading the next page from the server when needed.
+// It returns false when the rows are exhausted or an error occurred, in which case the connection
+// is released and Err reports the error
+func (it *AggregateIterator) Next() bool {
+	for it.pos >= len(it.rows) {
+		if it.closed || it.err != nil || (it.started && it.cursorId == 0) {
+			it.Close()
+			return false
+		}
+		if err := it.fetch(); err != nil {
+			it.err = err
+			it.Close()
+			return false
+		}
+	}
+	it.row = it.rows[it.pos]
+	it.pos++
+	return true
+}
+
+// Row returns the current row
+func (it *AggregateIterator) Row() AggregateRow {
+	return it.row
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *AggregateIterator) Err() error {
+	return it.err
+}
+
+// Close deletes the cursor on the server if it was not exhausted, and releases the connection.
+// If the connection is broken, e.g. by a canceled context, the cursor cannot be deleted: another connection
+// of the pool may reach a server that does not hold it. The error of Close then reports that the cursor is left
+// until its MAXIDLE timeout. It is safe to call Close more than once
+func (it *AggregateIterator) Close() (err error) {
+	if it.closed {
+		return nil
+	}
+	it.closed = true
+	it.rows, it.pos = nil, 0
+	if it.cursorId != 0 {
+		if connErr := it.conn.Err(); connErr != nil {
+			err = fmt.Errorf("AggregateIterator: cursor %d not deleted, its connection is broken: %v", it.cursorId, connErr)
+		} else {
+			// the context of the iterator may be done already: the cursor is deleted regardless
+			_, err = it.conn.Do("FT.CURSOR", "DEL", it.client.name, it.cursorId)
+		}
+		it.cursorId = 0
+	}
+	if it.conn != nil {
+		it.conn.Close()
+		it.conn = nil
+	}
+	return
+}
+
+// fetch reads the next page of rows, running the aggregation on the first call
+func (it *AggregateIterator) fetch() error {
+	var reply interface{}
+	var err error
+	if !it.started {
+		var queryArgs redis.Args
+		if queryArgs, err = it.query.serialize(); err != nil {
+			return err
+		}
+		if it.conn, err = it.client.getReadConn(it.ctx); err != nil {
+			return err
+		}
+		it.started = true
+		args := redis.Args{it.client.name}
+		args = append(args, queryArgs...)
+		reply, err = doContext(it.ctx, it.conn, "FT.AGGREGATE", args...)
+	} else {
+		args := redis.Args{"READ", it.client.name, it.cursorId}
+		if it.query.Cursor.Count > 0 {
+			args = args.Add("COUNT", it.query.Cursor.Count)
+		}
+		reply, err = doContext(it.ctx, it.conn, "FT.CURSOR", args...)
+	}
+	res, err := redis.Values(reply, err)
+	if err != nil {
+		return err
+	}
+	// with a cursor the reply is [rows, cursor id]
+	if len(res) != 2 {
+		return fmt.Errorf("Invalid cursor reply size: %d", len(res))
+	}
+	if it.cursorId, err = redis.Int(res[1], nil); err != nil {
+		return err
+	}
+	rows, err := redis.Values(res[0], nil)
+	if err != nil {
+		return err
+	}
+	page, err := newAggregateResult(rows)
+	if err != nil {
+		return err
+	}
+	it.rows, it.pos = page.Rows, 0
+	return nil
+}
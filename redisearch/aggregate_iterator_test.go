@@ -0,0 +1,103 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func cursorPage(cursorId int64, brands ...string) []interface{} {
+	rows := []interface{}{int64(len(brands))}
+	for _, brand := range brands {
+		rows = append(rows, []interface{}{[]byte("brand"), []byte(brand)})
+	}
+	return []interface{}{rows, cursorId}
+}
+
+func TestAggregateIterator(t *testing.T) {
+	conn := &scriptedConn{replies: []interface{}{
+		cursorPage(42, "sony", "nintendo"),
+		cursorPage(42),
+		cursorPage(0, "sega"),
+	}}
+	pool := &scriptedPool{conn: conn}
+	c := NewClientFromPool(pool, "idx")
+	q := NewAggregateQuery().SetCursor(NewCursor().SetCount(2))
+
+	it := c.AggregateIter(q)
+	brands := []string{}
+	for it.Next() {
+		brand, err := it.Row().String("brand")
+		assert.Nil(t, err)
+		brands = append(brands, brand)
+	}
+	assert.Nil(t, it.Err())
+	assert.Equal(t, []string{"sony", "nintendo", "sega"}, brands)
+	assert.Equal(t, []string{
+		"FT.AGGREGATE idx * WITHCURSOR COUNT 2",
+		"FT.CURSOR READ idx 42 COUNT 2",
+		"FT.CURSOR READ idx 42 COUNT 2",
+	}, conn.commands)
+	assert.Equal(t, 1, pool.gets)
+	assert.Equal(t, 1, conn.closed)
+	// the query of the caller is left untouched
+	assert.Equal(t, 0, q.Cursor.Id)
+
+	assert.Nil(t, it.Close())
+	assert.False(t, it.Next())
+	assert.Equal(t, 1, conn.closed)
+}
+
+func TestAggregateIterator_Close(t *testing.T) {
+	conn := &scriptedConn{replies: []interface{}{cursorPage(42, "sony", "nintendo"), "OK"}}
+	c := NewClientFromPool(&scriptedPool{conn: conn}, "idx")
+
+	it := c.AggregateIter(NewAggregateQuery())
+	assert.True(t, it.Next())
+	assert.Nil(t, it.Close())
+	assert.False(t, it.Next())
+	assert.Nil(t, it.Err())
+	assert.Equal(t, []string{"FT.AGGREGATE idx * WITHCURSOR", "FT.CURSOR DEL idx 42"}, conn.commands)
+	assert.Equal(t, 1, conn.closed)
+}
+
+func TestAggregateIterator_Close_broken(t *testing.T) {
+	conn := &scriptedConn{replies: []interface{}{cursorPage(42, "sony", "nintendo"), "OK"}}
+	pool := &scriptedPool{conn: conn}
+	c := NewClientFromPool(pool, "idx")
+
+	it := c.AggregateIter(NewAggregateQuery())
+	assert.True(t, it.Next())
+	conn.err = errors.New("broken")
+	// another connection may reach a server without the cursor
+	err := it.Close()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "cursor 42 not deleted")
+	assert.Equal(t, []string{"FT.AGGREGATE idx * WITHCURSOR"}, conn.commands)
+	assert.Equal(t, 1, pool.gets)
+	assert.Equal(t, 1, conn.closed)
+	assert.Nil(t, it.Close())
+}
+
+func TestAggregateIterator_errors(t *testing.T) {
+	conn := &scriptedConn{replies: []interface{}{cursorPage(42, "sony"), redis.Error("Cursor not found"), "OK"}}
+	c := NewClientFromPool(&scriptedPool{conn: conn}, "idx")
+
+	it := c.AggregateIter(NewAggregateQuery())
+	assert.True(t, it.Next())
+	assert.False(t, it.Next())
+	assert.Equal(t, redis.Error("Cursor not found"), it.Err())
+	assert.Equal(t, "FT.CURSOR DEL idx 42", conn.commands[len(conn.commands)-1])
+
+	conn = &scriptedConn{}
+	c = NewClientFromPool(&scriptedPool{conn: conn}, "idx")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	it = c.AggregateIterContext(ctx, NewAggregateQuery())
+	assert.False(t, it.Next())
+	assert.Equal(t, context.Canceled, it.Err())
+	assert.Equal(t, 0, len(conn.commands))
+}
@@ -0,0 +1,250 @@
+package redisearch
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// ErrNoSuchField is returned by the AggregateRow accessors when the row has no such field
+var ErrNoSuchField = errors.New("no such field in aggregate row")
+
+// AggregateRow is a single row of an aggregation result.
+// Values are either nil, a string, an int64 or a []interface{} of values for array outputs (e.g. TOLIST)
+type AggregateRow struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// Keys returns the field names of the row, in the order returned by the server
+func (r AggregateRow) Keys() []string {
+	return r.keys
+}
+
+// Len returns the number of fields in the row
+func (r AggregateRow) Len() int {
+	return len(r.keys)
+}
+
+// Get returns the raw value of a field, and whether the row has such a field
+func (r AggregateRow) Get(key string) (value interface{}, found bool) {
+	value, found = r.values[key]
+	return
+}
+
+// IsNil returns true if the field is missing or has no value in the row
+func (r AggregateRow) IsNil(key string) bool {
+	return r.values[key] == nil
+}
+
+// Map returns the fields of the row as a map
+func (r AggregateRow) Map() map[string]interface{} {
+	ret := make(map[string]interface{}, len(r.values))
+	for k, v := range r.values {
+		ret[k] = v
+	}
+	return ret
+}
+
+func (r AggregateRow) value(key string) (interface{}, error) {
+	value, found := r.values[key]
+	if !found {
+		return nil, ErrNoSuchField
+	}
+	if value == nil {
+		return nil, redis.ErrNil
+	}
+	return value, nil
+}
+
+// String returns a field value as a string
+func (r AggregateRow) String(key string) (string, error) {
+	value, err := r.value(key)
+	if err != nil {
+		return "", err
+	}
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	}
+	return "", fmt.Errorf("field %s: unexpected type %T for a string", key, value)
+}
+
+// Float64 returns a field value as a float64
+func (r AggregateRow) Float64(key string) (float64, error) {
+	value, err := r.value(key)
+	if err != nil {
+		return 0, err
+	}
+	switch v := value.(type) {
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("field %s: %s", key, err)
+		}
+		return f, nil
+	case int64:
+		return float64(v), nil
+	}
+	return 0, fmt.Errorf("field %s: unexpected type %T for a float", key, value)
+}
+
+// Int64 returns a field value as an int64
+func (r AggregateRow) Int64(key string) (int64, error) {
+	value, err := r.value(key)
+	if err != nil {
+		return 0, err
+	}
+	if s, ok := value.(string); ok {
+		// numeric values are returned as strings, possibly with a decimal point
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("field %s: %s", key, err)
+		}
+		return int64(f), nil
+	}
+	n, err := redis.Int64(value, nil)
+	if err != nil {
+		return 0, fmt.Errorf("field %s: %s", key, err)
+	}
+	return n, nil
+}
+
+// Strings returns an array field value, like the output of the TOLIST reducer, as a slice of strings.
+// A single value is returned as a slice of one element
+func (r AggregateRow) Strings(key string) ([]string, error) {
+	value, err := r.value(key)
+	if err != nil {
+		return nil, err
+	}
+	values, ok := value.([]interface{})
+	if !ok {
+		values = []interface{}{value}
+	}
+	ret := make([]string, 0, len(values))
+	for _, v := range values {
+		switch v := v.(type) {
+		case string:
+			ret = append(ret, v)
+		case int64:
+			ret = append(ret, strconv.FormatInt(v, 10))
+		case nil:
+			ret = append(ret, "")
+		default:
+			return nil, fmt.Errorf("field %s: unexpected element type %T for a string", key, v)
+		}
+	}
+	return ret, nil
+}
+
+// Decode stores the row fields in the redisearch tagged fields of the struct pointed by v,
+// see Document.Decode
+func (r AggregateRow) Decode(v interface{}) error {
+	doc := Document{Properties: r.values}
+	return doc.Decode(v)
+}
+
+// AggregateResult is the reply of an aggregation
+type AggregateResult struct {
+	// Total is the number of results reported by the server
+	Total int
+	Rows  []AggregateRow
+}
+
+// Decode stores the rows in the slice pointed by v, whose elements are structs or pointers to structs
+// with redisearch tagged fields
+func (a *AggregateResult) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("Decode: expected a pointer to a slice, got %T", v)
+	}
+	slice := rv.Elem()
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	out := reflect.MakeSlice(slice.Type(), len(a.Rows), len(a.Rows))
+	for i, row := range a.Rows {
+		elem := reflect.New(elemType)
+		if err := row.Decode(elem.Interface()); err != nil {
+			return fmt.Errorf("row %d: %s", i, err)
+		}
+		if isPtr {
+			out.Index(i).Set(elem)
+		} else {
+			out.Index(i).Set(elem.Elem())
+		}
+	}
+	slice.Set(out)
+	return nil
+}
+
+// newAggregateResult parses a FT.AGGREGATE reply: the total followed by one array of
+// field/value pairs per row
+func newAggregateResult(res []interface{}) (*AggregateResult, error) {
+	ret := &AggregateResult{Rows: []AggregateRow{}}
+	if len(res) == 0 {
+		return ret, nil
+	}
+	if total, err := redis.Int(res[0], nil); err == nil {
+		ret.Total = total
+	}
+	ret.Rows = make([]AggregateRow, 0, len(res)-1)
+	for i := 1; i < len(res); i++ {
+		row, err := parseAggregateRow(res[i])
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing Aggregate Reply: %v on reply position %d", err, i-1)
+		}
+		ret.Rows = append(ret.Rows, row)
+	}
+	return ret, nil
+}
+
+func parseAggregateRow(reply interface{}) (AggregateRow, error) {
+	fields, err := redis.Values(reply, nil)
+	if err != nil {
+		return AggregateRow{}, err
+	}
+	if len(fields)%2 != 0 {
+		return AggregateRow{}, fmt.Errorf("expected an even number of field/value elements, got %d", len(fields))
+	}
+	row := AggregateRow{
+		keys:   make([]string, 0, len(fields)/2),
+		values: make(map[string]interface{}, len(fields)/2),
+	}
+	for i := 0; i < len(fields); i += 2 {
+		key, err := redis.String(fields[i], nil)
+		if err != nil {
+			return AggregateRow{}, err
+		}
+		if _, found := row.values[key]; !found {
+			row.keys = append(row.keys, key)
+		}
+		row.values[key] = normalizeAggregateValue(fields[i+1])
+	}
+	return row, nil
+}
+
+// normalizeAggregateValue converts bulk strings to strings, recursively for array values
+func normalizeAggregateValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i := range v {
+			values[i] = normalizeAggregateValue(v[i])
+		}
+		return values
+	}
+	return value
+}
@@ -0,0 +1,113 @@
+package redisearch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testAggregateReply() []interface{} {
+	return []interface{}{
+		int64(2),
+		[]interface{}{[]byte("brand"), []byte("sony"), []byte("avg_price"), []byte("88.5"), []byte("count"), int64(3),
+			[]byte("titles"), []interface{}{[]byte("a"), []byte("b")}},
+		[]interface{}{[]byte("brand"), nil, []byte("avg_price"), []byte("10"), []byte("count"), int64(1),
+			[]byte("titles"), []interface{}{}},
+	}
+}
+
+func Test_newAggregateResult(t *testing.T) {
+	res, err := newAggregateResult(testAggregateReply())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Total != 2 || len(res.Rows) != 2 {
+		t.Fatalf("newAggregateResult() total = %d, rows = %d", res.Total, len(res.Rows))
+	}
+	row := res.Rows[0]
+	if want := []string{"brand", "avg_price", "count", "titles"}; !reflect.DeepEqual(row.Keys(), want) {
+		t.Errorf("Keys() = %v, want %v", row.Keys(), want)
+	}
+	if s, err := row.String("brand"); err != nil || s != "sony" {
+		t.Errorf("String() = %v, %v", s, err)
+	}
+	if f, err := row.Float64("avg_price"); err != nil || f != 88.5 {
+		t.Errorf("Float64() = %v, %v", f, err)
+	}
+	if f, err := row.Float64("count"); err != nil || f != 3 {
+		t.Errorf("Float64() = %v, %v", f, err)
+	}
+	if n, err := row.Int64("count"); err != nil || n != 3 {
+		t.Errorf("Int64() = %v, %v", n, err)
+	}
+	if n, err := row.Int64("avg_price"); err != nil || n != 88 {
+		t.Errorf("Int64() = %v, %v", n, err)
+	}
+	if l, err := row.Strings("titles"); err != nil || !reflect.DeepEqual(l, []string{"a", "b"}) {
+		t.Errorf("Strings() = %v, %v", l, err)
+	}
+	if l, err := row.Strings("brand"); err != nil || !reflect.DeepEqual(l, []string{"sony"}) {
+		t.Errorf("Strings() = %v, %v", l, err)
+	}
+	if _, err := row.Float64("missing"); err != ErrNoSuchField {
+		t.Errorf("Float64() error = %v, want %v", err, ErrNoSuchField)
+	}
+	if _, err := row.Float64("brand"); err == nil {
+		t.Errorf("Float64() expected a parsing error")
+	}
+	if !res.Rows[1].IsNil("brand") {
+		t.Errorf("IsNil() = false, want true")
+	}
+	if _, err := res.Rows[1].String("brand"); err == nil {
+		t.Errorf("String() expected an error for a nil value")
+	}
+}
+
+func Test_newAggregateResult_errors(t *testing.T) {
+	tests := []struct {
+		name string
+		res  []interface{}
+	}{
+		{"not-an-array", []interface{}{int64(1), []byte("brand")}},
+		{"odd-elements", []interface{}{int64(1), []interface{}{[]byte("brand")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newAggregateResult(tt.res); err == nil {
+				t.Errorf("newAggregateResult() expected an error")
+			}
+		})
+	}
+}
+
+type testBrandStats struct {
+	Brand    string   `redisearch:"brand"`
+	AvgPrice float64  `redisearch:"avg_price"`
+	Count    int      `redisearch:"count"`
+	Titles   []string `redisearch:"titles"`
+}
+
+func TestAggregateResult_Decode(t *testing.T) {
+	res, err := newAggregateResult(testAggregateReply())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stats []testBrandStats
+	if err := res.Decode(&stats); err != nil {
+		t.Fatal(err)
+	}
+	want := []testBrandStats{
+		{"sony", 88.5, 3, []string{"a", "b"}},
+		{"", 10, 1, []string{}},
+	}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("Decode() = %+v, want %+v", stats, want)
+	}
+
+	var ptrs []*testBrandStats
+	if err := res.Decode(&ptrs); err != nil || len(ptrs) != 2 || ptrs[0].Brand != "sony" {
+		t.Errorf("Decode() = %+v, %v", ptrs, err)
+	}
+	if err := res.Decode(stats); err == nil {
+		t.Errorf("Decode() expected an error for a non pointer value")
+	}
+}
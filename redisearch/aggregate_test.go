@@ -137,6 +137,37 @@ func TestAggregateGroupBy2(t *testing.T) {
 }
 
 
+func TestAggregateIter(t *testing.T) {
+	Init()
+	c := createClient("docs-games-idx1")
+
+	q := NewAggregateQuery().
+		GroupBy(*NewGroupBy().AddFields("@brand").
+			Reduce(*NewReducerAlias(GroupByReducerCount, []string{}, "count"))).
+		SetCursor(NewCursor().SetCount(100))
+
+	it := c.AggregateIter(q)
+	defer it.Close()
+	brands := 0
+	for it.Next() {
+		assert.False(t, it.Row().IsNil("count"))
+		brands++
+	}
+	assert.Nil(t, it.Err())
+
+	res, _, err := c.Aggregate(NewAggregateQuery().
+		GroupBy(*NewGroupBy().AddFields("@brand").
+			Reduce(*NewReducerAlias(GroupByReducerCount, []string{}, "count"))).
+		Limit(0, 10000))
+	assert.Nil(t, err)
+	assert.Equal(t, len(res), brands)
+
+	// abandoning the iteration deletes the cursor
+	it = c.AggregateIter(q)
+	assert.True(t, it.Next())
+	assert.Nil(t, it.Close())
+}
+
 func TestAggregateMinMax(t *testing.T) {
 	Init()
 	c := createClient("docs-games-idx1")
@@ -615,6 +646,18 @@ func TestAggregateQuery_Load(t *testing.T) {
 	}
 }
 
+func TestAggregateQuery_Args(t *testing.T) {
+	q := NewAggregateQuery().SetQuery(NewQuery("*").AddFilter(GreaterThan("price", 10)))
+	args, err := q.Args()
+	assert.Nil(t, err)
+	assert.Equal(t, q.Serialize(), args)
+
+	q = NewAggregateQuery().SetQuery(NewQuery("*").AddFilter(Equals("price", "ten")))
+	_, err = q.Args()
+	assert.NotNil(t, err)
+	assert.Empty(t, q.Serialize())
+}
+
 func TestProcessAggResponse(t *testing.T) {
 	type args struct {
 		res []interface{}
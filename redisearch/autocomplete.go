@@ -8,24 +8,35 @@ import (
 // Autocompleter implements a redisearch auto-completer API
 type Autocompleter struct {
 	name string
-	pool *redis.Pool
+	pool ConnPool
+
+	// readPool is the pool of FT.SUGGET, pool if nil
+	readPool ConnPool
 }
 
-// NewAutocompleter creates a new Autocompleter with the given pool and key name
-func NewAutocompleterFromPool(pool *redis.Pool, name string) *Autocompleter {
+// NewAutocompleter creates a new Autocompleter with the given pool and key name.
+// With a ClusterPool, the commands are sent to the node owning the key
+func NewAutocompleterFromPool(pool ConnPool, name string) *Autocompleter {
 	return &Autocompleter{name: name, pool: pool}
 }
 
-// NewAutocompleter creates a new Autocompleter with the given host and key name
-func NewAutocompleter(addr, name string) *Autocompleter {
+// NewAutocompleter creates a new Autocompleter with the given host and key name.
+// The connections are configured with opts, as the ones of NewClient
+func NewAutocompleter(addr, name string, opts ...ClientOption) *Autocompleter {
 	return &Autocompleter{
-		pool: redis.NewPool(func() (redis.Conn, error) {
-			return redis.Dial("tcp", addr)
-		}, maxConns),
+		pool: NewSingleHostPool(addr, opts...),
 		name: name,
 	}
 }
 
+// suggestPool returns the pool of FT.SUGGET
+func (a *Autocompleter) suggestPool() ConnPool {
+	if a.readPool != nil {
+		return a.readPool
+	}
+	return a.pool
+}
+
 // Delete deletes the Autocompleter key for this AC
 func (a *Autocompleter) Delete() error {
 	conn := a.pool.Get()
@@ -105,7 +116,7 @@ func (a *Autocompleter) Length() (len int64, err error) {
 //
 // Deprecated: Please use SuggestOpts() instead
 func (a *Autocompleter) Suggest(prefix string, num int, fuzzy bool) (ret []Suggestion, err error) {
-	conn := a.pool.Get()
+	conn := a.suggestPool().Get()
 	defer conn.Close()
 
 	seropts := DefaultSuggestOptions
@@ -128,7 +139,7 @@ func (a *Autocompleter) Suggest(prefix string, num int, fuzzy bool) (ret []Sugge
 // If SuggestOptions.Fuzzy is set, we also complete for prefixes that are in 1 Levenshtein distance from the
 // given prefix
 func (a *Autocompleter) SuggestOpts(prefix string, opts SuggestOptions) (ret []Suggestion, err error) {
-	conn := a.pool.Get()
+	conn := a.suggestPool().Get()
 	defer conn.Close()
 
 	args, inc := a.Serialize(prefix, opts)
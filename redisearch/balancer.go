@@ -0,0 +1,99 @@
+package redisearch
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// Balancer picks the host a MultiHostPool gets a connection from
+type Balancer interface {
+	// Pick returns the index of the host to get a connection from, among hosts, which is never empty.
+	// It is called concurrently
+	Pick(hosts []*PoolHost) int
+}
+
+// BalancerFunc adapts a function to a Balancer
+type BalancerFunc func(hosts []*PoolHost) int
+
+// Pick calls f(hosts)
+func (f BalancerFunc) Pick(hosts []*PoolHost) int {
+	return f(hosts)
+}
+
+// RandomBalancer picks a host at random. It is the default
+func RandomBalancer() Balancer {
+	return BalancerFunc(func(hosts []*PoolHost) int {
+		return rand.Intn(len(hosts))
+	})
+}
+
+// RoundRobinBalancer picks the hosts in turn
+func RoundRobinBalancer() Balancer {
+	return &roundRobinBalancer{}
+}
+
+type roundRobinBalancer struct {
+	next uint32
+}
+
+func (b *roundRobinBalancer) Pick(hosts []*PoolHost) int {
+	return int((atomic.AddUint32(&b.next, 1) - 1) % uint32(len(hosts)))
+}
+
+// LeastActiveBalancer picks the host with the fewest connections in use, not counting the idle ones.
+// Ties are broken at random
+func LeastActiveBalancer() Balancer {
+	return BalancerFunc(func(hosts []*PoolHost) int {
+		start := rand.Intn(len(hosts))
+		best, bestActive := start, hosts[start].ActiveCount()-hosts[start].IdleCount()
+		for ii := 1; ii < len(hosts); ii++ {
+			jj := (start + ii) % len(hosts)
+			if active := hosts[jj].ActiveCount() - hosts[jj].IdleCount(); active < bestActive {
+				best, bestActive = jj, active
+			}
+		}
+		return best
+	})
+}
+
+// minLatency bounds the latencies weighting the hosts of LatencyBalancer, so that no host takes all the load
+const minLatency = 100 * time.Microsecond
+
+// LatencyBalancer picks a host at random, with a probability inversely proportional to its latency, as measured
+// by the health checks enabled with WithHealthCheck. Hosts without measures are given the average latency
+func LatencyBalancer() Balancer {
+	return BalancerFunc(func(hosts []*PoolHost) int {
+		latencies := make([]time.Duration, len(hosts))
+		var sum time.Duration
+		var measured int
+		for ii, h := range hosts {
+			if latencies[ii] = h.Latency(); latencies[ii] > 0 {
+				sum += latencies[ii]
+				measured++
+			}
+		}
+		if measured == 0 {
+			return rand.Intn(len(hosts))
+		}
+		weights := make([]float64, len(hosts))
+		var total float64
+		for ii, latency := range latencies {
+			if latency == 0 {
+				latency = sum / time.Duration(measured)
+			}
+			if latency < minLatency {
+				latency = minLatency
+			}
+			weights[ii] = 1 / float64(latency)
+			total += weights[ii]
+		}
+		r := rand.Float64() * total
+		for ii, w := range weights {
+			if r -= w; r < 0 {
+				return ii
+			}
+		}
+		return len(hosts) - 1
+	})
+}
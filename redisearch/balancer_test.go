@@ -0,0 +1,177 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+// testHostPool is a hostPool returning conn, or connections failing with err
+type testHostPool struct {
+	conn   *scriptedConn
+	err    error
+	active int
+	idle   int
+	gets   int
+	closed int
+}
+
+func (p *testHostPool) Get() redis.Conn {
+	p.gets++
+	if p.err != nil {
+		return &scriptedConn{err: p.err}
+	}
+	return p.conn
+}
+
+func (p *testHostPool) GetContext(ctx context.Context) (redis.Conn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	conn := p.Get()
+	return conn, conn.Err()
+}
+
+func (p *testHostPool) ActiveCount() int {
+	return p.active
+}
+
+func (p *testHostPool) IdleCount() int {
+	return p.idle
+}
+
+func (p *testHostPool) Close() error {
+	p.closed++
+	return nil
+}
+
+func testHosts(pools ...*testHostPool) []*PoolHost {
+	var hosts []*PoolHost
+	for _, pool := range pools {
+		hosts = append(hosts, &PoolHost{pool: pool})
+	}
+	return hosts
+}
+
+func TestBalancers(t *testing.T) {
+	hosts := testHosts(&testHostPool{active: 3}, &testHostPool{active: 1}, &testHostPool{active: 2})
+
+	rr := RoundRobinBalancer()
+	var picks []int
+	for ii := 0; ii < 4; ii++ {
+		picks = append(picks, rr.Pick(hosts))
+	}
+	assert.Equal(t, []int{0, 1, 2, 0}, picks)
+
+	assert.Equal(t, 1, LeastActiveBalancer().Pick(hosts))
+	// idle connections are not in use
+	warm := testHosts(&testHostPool{active: 3, idle: 2}, &testHostPool{active: 3})
+	for ii := 0; ii < 10; ii++ {
+		assert.Equal(t, 0, LeastActiveBalancer().Pick(warm))
+	}
+
+	for _, b := range []Balancer{RandomBalancer(), LatencyBalancer()} {
+		ii := b.Pick(hosts)
+		assert.True(t, ii >= 0 && ii < len(hosts))
+	}
+
+	hosts[0].latency, hosts[1].latency = int64(time.Millisecond), int64(10*time.Millisecond)
+	counts := make([]int, len(hosts))
+	for ii := 0; ii < 1000; ii++ {
+		counts[LatencyBalancer().Pick(hosts)]++
+	}
+	// the host without measures is given the average latency of the others
+	assert.True(t, counts[0] > counts[2] && counts[2] > counts[1])
+}
+
+func TestMultiHostPool_ejection(t *testing.T) {
+	a := &testHostPool{err: errors.New("connection refused")}
+	b := &testHostPool{conn: &scriptedConn{}}
+	o := newClientOptions([]ClientOption{WithBalancer(BalancerFunc(func(hosts []*PoolHost) int { return 0 })),
+		WithEjection(time.Second, 4*time.Second)})
+	p := newMultiHostPool([]string{"a:6379", "b:6379"}, o, func(addr string) hostPool {
+		if addr == "a:6379" {
+			return a
+		}
+		return b
+	})
+	now := time.Unix(1000, 0)
+	p.now = func() time.Time { return now }
+	ha := p.all[0]
+
+	// a fails, the connection comes from b
+	assert.Nil(t, p.Get().Err())
+	assert.Equal(t, 1, ha.Failures())
+	assert.Equal(t, now.Add(time.Second).UnixNano(), ha.ejectedUntil)
+
+	// a is not tried while ejected
+	assert.Nil(t, p.Get().Err())
+	assert.Equal(t, 1, a.gets)
+
+	// the ejection doubles at each failure, up to the maximum
+	for _, delay := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		now = now.Add(delay)
+		assert.Nil(t, p.Get().Err())
+	}
+	assert.Equal(t, 4, ha.Failures())
+	assert.Equal(t, now.Add(4*time.Second).UnixNano(), ha.ejectedUntil)
+
+	// the failures are reset when a is re-admitted and succeeds
+	a.err, a.conn = nil, &scriptedConn{}
+	now = now.Add(4 * time.Second)
+	assert.Nil(t, p.Get().Err())
+	assert.Equal(t, 0, ha.Failures())
+	assert.Equal(t, 2, len(p.available()))
+
+	// when all the hosts are ejected, they are all tried
+	a.err, b.err = errors.New("down"), errors.New("down")
+	assert.NotNil(t, p.Get().Err())
+	assert.Equal(t, 0, len(p.healthy.Load().([]*PoolHost)))
+	assert.Equal(t, 2, len(p.available()))
+
+	// a canceled context does not eject the host
+	b.err = nil
+	p.all[1].ejectedUntil = 0
+	p.update()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := p.GetContext(ctx)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, int64(0), p.all[1].ejectedUntil)
+}
+
+func TestMultiHostPool_checkHealth(t *testing.T) {
+	a := &testHostPool{conn: &scriptedConn{replies: []interface{}{"PONG"}}}
+	b := &testHostPool{err: errors.New("connection refused")}
+	p := newMultiHostPool([]string{"a:6379", "b:6379"}, newClientOptions(nil), func(addr string) hostPool {
+		if addr == "a:6379" {
+			return a
+		}
+		return b
+	})
+	// every reading of the clock is a millisecond later
+	var ticks int64
+	p.now = func() time.Time {
+		return time.Unix(0, atomic.AddInt64(&ticks, 1)*int64(time.Millisecond))
+	}
+
+	p.checkHealth(time.Second)
+	assert.Equal(t, []string{"PING"}, a.conn.commands)
+	assert.True(t, p.all[0].Latency() > 0)
+	assert.Equal(t, 1, p.all[1].Failures())
+	assert.Equal(t, []*PoolHost{p.all[0]}, p.healthy.Load().([]*PoolHost))
+
+	// ejected hosts are not checked
+	p.checkHealth(time.Second)
+	assert.Equal(t, 1, b.gets)
+
+	assert.Nil(t, p.Close())
+	assert.Nil(t, p.Close())
+	assert.Equal(t, 1, a.closed)
+	assert.Equal(t, 1, b.closed)
+}
@@ -0,0 +1,242 @@
+package redisearch
+
+import (
+	"crypto/tls"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// ClientOption configures the connections of the pools created by NewClient, NewAutocompleter, NewSingleHostPool,
+// NewMultiHostPool, NewClusterPool and NewSentinelPool
+type ClientOption func(*clientOptions)
+
+// clientOptions are the settings of the connections and of the pool of a host
+type clientOptions struct {
+	username  string
+	password  string
+	database  int
+	tlsConfig *tls.Config
+	useTLS    bool
+
+	connectTimeout time.Duration
+	readTimeout    time.Duration
+	writeTimeout   time.Duration
+
+	maxIdle     int
+	maxActive   int
+	idleTimeout time.Duration
+	wait        bool
+
+	// balancer, healthCheckInterval, ejectBase and ejectMax configure MultiHostPool
+	balancer            Balancer
+	healthCheckInterval time.Duration
+	ejectBase           time.Duration
+	ejectMax            time.Duration
+
+	// sentinelUsername and sentinelPassword authenticate the connections to the sentinels of NewSentinelPool
+	sentinelUsername string
+	sentinelPassword string
+
+	// queryPool is set by WithQueryOptions, changing the options of the query pool of NewClient with queryOptions
+	queryPool    bool
+	queryOptions []ClientOption
+}
+
+// newClientOptions returns the default settings, changed by opts
+func newClientOptions(opts []ClientOption) *clientOptions {
+	o := &clientOptions{
+		maxIdle:   maxConns,
+		balancer:  RandomBalancer(),
+		ejectBase: time.Second,
+		ejectMax:  time.Minute,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithAuth authenticates the connections with AUTH. The username of an ACL user can be empty for the default user
+func WithAuth(username, password string) ClientOption {
+	return func(o *clientOptions) {
+		o.username, o.password = username, password
+	}
+}
+
+// WithTLSConfig connects over TLS with the given configuration, or the default one if nil
+func WithTLSConfig(config *tls.Config) ClientOption {
+	return func(o *clientOptions) {
+		o.useTLS, o.tlsConfig = true, config
+	}
+}
+
+// WithDatabase selects the database of the connections with SELECT
+func WithDatabase(db int) ClientOption {
+	return func(o *clientOptions) {
+		o.database = db
+	}
+}
+
+// WithSentinelAuth authenticates the connections of NewSentinelPool to the sentinels with AUTH.
+// The sentinels are not authenticated by default, as WithAuth only applies to the Redis nodes
+func WithSentinelAuth(username, password string) ClientOption {
+	return func(o *clientOptions) {
+		o.sentinelUsername, o.sentinelPassword = username, password
+	}
+}
+
+// WithConnectTimeout sets the timeout for connecting to the host. No timeout by default
+func WithConnectTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.connectTimeout = timeout
+	}
+}
+
+// WithReadTimeout sets the timeout for reading a reply. No timeout by default
+func WithReadTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.readTimeout = timeout
+	}
+}
+
+// WithWriteTimeout sets the timeout for writing a command. No timeout by default
+func WithWriteTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.writeTimeout = timeout
+	}
+}
+
+// WithMaxIdle sets the maximum number of idle connections kept by the pool of a host, 500 by default
+func WithMaxIdle(maxIdle int) ClientOption {
+	return func(o *clientOptions) {
+		o.maxIdle = maxIdle
+	}
+}
+
+// WithMaxActive sets the maximum number of connections open to a host at the same time. Unlimited if zero, the default
+func WithMaxActive(maxActive int) ClientOption {
+	return func(o *clientOptions) {
+		o.maxActive = maxActive
+	}
+}
+
+// WithIdleTimeout closes the connections idle for longer than timeout. Idle connections are kept open by default
+func WithIdleTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.idleTimeout = timeout
+	}
+}
+
+// WithWait makes the pool wait for a connection to be returned when MaxActive connections are open,
+// instead of failing with an error. The context-aware Client methods wait within the deadline of their context
+func WithWait(wait bool) ClientOption {
+	return func(o *clientOptions) {
+		o.wait = wait
+	}
+}
+
+// WithBalancer sets how a MultiHostPool picks the host of a connection, RandomBalancer by default
+func WithBalancer(balancer Balancer) ClientOption {
+	return func(o *clientOptions) {
+		o.balancer = balancer
+	}
+}
+
+// WithHealthCheck makes a MultiHostPool send PING to its hosts in the background at every interval,
+// ejecting the failing ones and measuring the latency of the others. The pool must then be closed,
+// with Client.Close for the clients created by NewClient. No health checks by default
+func WithHealthCheck(interval time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.healthCheckInterval = interval
+	}
+}
+
+// WithEjection sets for how long a MultiHostPool ejects a failing host: base after the first failure, doubling
+// at each consecutive failure up to max. The defaults are 1 second and 1 minute. A zero base disables ejection
+func WithEjection(base, max time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.ejectBase, o.ejectMax = base, max
+	}
+}
+
+// WithQueryOptions makes NewClient send the query commands, i.e. FT.SEARCH, FT.AGGREGATE, FT.GET or FT.SPELLCHECK,
+// to a separate pool, configured with the options of the client changed by opts, i.e.
+//
+//	c := NewClient("localhost:6379", "idx", WithReadTimeout(time.Minute),
+//		WithQueryOptions(WithReadTimeout(500*time.Millisecond), WithMaxActive(50)))
+//
+// Client.Close closes both pools
+func WithQueryOptions(opts ...ClientOption) ClientOption {
+	return func(o *clientOptions) {
+		o.queryPool = true
+		o.queryOptions = append(o.queryOptions, opts...)
+	}
+}
+
+// sentinelOptions returns the settings of the connections to the sentinels: the ones of the nodes,
+// with the credentials of WithSentinelAuth and without SELECT, which the sentinels reject
+func (o *clientOptions) sentinelOptions() *clientOptions {
+	so := *o
+	so.username, so.password = o.sentinelUsername, o.sentinelPassword
+	so.database = 0
+	return &so
+}
+
+// dialOptions returns the options of redis.Dial
+func (o *clientOptions) dialOptions() []redis.DialOption {
+	var opts []redis.DialOption
+	if o.username != "" {
+		opts = append(opts, redis.DialUsername(o.username))
+	}
+	if o.password != "" {
+		opts = append(opts, redis.DialPassword(o.password))
+	}
+	if o.database != 0 {
+		opts = append(opts, redis.DialDatabase(o.database))
+	}
+	if o.useTLS {
+		opts = append(opts, redis.DialUseTLS(true))
+		if o.tlsConfig != nil {
+			opts = append(opts, redis.DialTLSConfig(o.tlsConfig))
+		}
+	}
+	if o.connectTimeout > 0 {
+		opts = append(opts, redis.DialConnectTimeout(o.connectTimeout))
+	}
+	if o.readTimeout > 0 {
+		opts = append(opts, redis.DialReadTimeout(o.readTimeout))
+	}
+	if o.writeTimeout > 0 {
+		opts = append(opts, redis.DialWriteTimeout(o.writeTimeout))
+	}
+	return opts
+}
+
+// newPool creates the pool of the connections to a host, given as host:port or as a redis:// or rediss:// URL.
+// The username, password and database of a URL take precedence over the options
+func (o *clientOptions) newPool(host string) *redis.Pool {
+	dialOptions := o.dialOptions()
+	pool := redis.NewPool(func() (redis.Conn, error) {
+		if isRedisURL(host) {
+			return redis.DialURL(host, dialOptions...)
+		}
+		return redis.Dial("tcp", host, dialOptions...)
+	}, o.maxIdle)
+	pool.MaxActive = o.maxActive
+	pool.IdleTimeout = o.idleTimeout
+	pool.Wait = o.wait
+	pool.TestOnBorrow = func(c redis.Conn, t time.Time) (err error) {
+		if time.Since(t) > time.Second {
+			_, err = c.Do("PING")
+		}
+		return err
+	}
+	return pool
+}
+
+// isRedisURL returns whether addr is a redis:// or rediss:// URL rather than a host:port pair
+func isRedisURL(addr string) bool {
+	return strings.HasPrefix(addr, "redis://") || strings.HasPrefix(addr, "rediss://")
+}
@@ -1,12 +1,18 @@
 package redisearch
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"github.com/gomodule/redigo/redis"
 	"github.com/stretchr/testify/assert"
 	"log"
 	"os"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 )
 
 func init() {
@@ -476,3 +482,549 @@ func TestClient_AliasUpdate(t *testing.T) {
 		})
 	}
 }
+
+// cancelingConn is a redis.Conn that cancels the context while a command is in flight
+type cancelingConn struct {
+	redis.Conn
+	cancel   context.CancelFunc
+	commands []string
+}
+
+func (c *cancelingConn) Do(commandName string, args ...interface{}) (interface{}, error) {
+	c.commands = append(c.commands, commandName)
+	c.cancel()
+	return nil, errors.New("i/o timeout")
+}
+
+func Test_doContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	conn := &cancelingConn{cancel: cancel}
+
+	_, err := doContext(ctx, conn, "FT.SEARCH", "idx", "*")
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, []string{"FT.SEARCH"}, conn.commands)
+
+	// an already canceled context does not send the command at all
+	_, err = doContext(ctx, conn, "FT.SEARCH", "idx", "*")
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 1, len(conn.commands))
+}
+
+func TestClient_SearchContext(t *testing.T) {
+	c := createClient("testsearchcontext")
+	c.Drop()
+	assert.Nil(t, c.CreateIndexContext(context.Background(), NewSchema(DefaultOptions).AddField(NewTextField("foo"))))
+
+	docs := make([]Document, 10)
+	for i := 0; i < 10; i++ {
+		docs[i] = NewDocument(fmt.Sprintf("doc-ctx-%d", i), 1).Set("foo", "hello world")
+	}
+	assert.Nil(t, c.IndexContext(context.Background(), docs...))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, total, err := c.SearchContext(ctx, NewQuery("hello"))
+	assert.Nil(t, err)
+	assert.Equal(t, 10, total)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = c.SearchContext(canceled, NewQuery("hello"))
+	assert.Equal(t, context.Canceled, err)
+	_, _, err = c.AggregateContext(canceled, NewAggregateQuery().SetQuery(NewQuery("hello")))
+	assert.Equal(t, context.Canceled, err)
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	_, err = c.InfoContext(expired)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestClient_CreateIndexWithIndexDefinition(t *testing.T) {
+	c := createClient("testdefinition")
+	c.Drop()
+	sc := NewSchema(DefaultOptions).AddField(NewTextField("title")).AddField(NewNumericField("price"))
+	def := NewIndexDefinition().AddPrefix("testdefinition:").SetFilterExpression("@price>0")
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(sc, def))
+	defer c.Drop()
+
+	conn := c.pool.Get()
+	defer conn.Close()
+	_, err := conn.Do("HSET", "testdefinition:1", "title", "hello world", "price", 10)
+	assert.Nil(t, err)
+	_, err = conn.Do("HSET", "testdefinition:2", "title", "hello world", "price", 0)
+	assert.Nil(t, err)
+	_, err = conn.Do("HSET", "other:1", "title", "hello world", "price", 10)
+	assert.Nil(t, err)
+
+	docs, total, err := c.Search(NewQuery("hello"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "testdefinition:1", docs[0].Id)
+}
+
+func TestHashWriter(t *testing.T) {
+	c := createClient("testhashwriter")
+	c.Drop()
+	def := NewIndexDefinition().AddPrefix("testhashwriter:")
+	sc := NewSchema(DefaultOptions).AddField(NewTextField("title")).AddField(NewNumericField("price"))
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(sc, def))
+	defer c.Drop()
+
+	w := NewHashWriter(c, def)
+	assert.Nil(t, w.Index(NewDocument("doc1", 1).Set("title", "hello world").Set("price", 10),
+		NewDocument("doc2", 1).Set("title", "hello world").Set("price", 20)))
+	// documents are added only once
+	err := w.Index(NewDocument("doc1", 1).Set("title", "hello world"))
+	assert.NotNil(t, err)
+
+	assert.Nil(t, w.IndexOptions(IndexingOptions{Partial: true, ReplaceCondition: "@price < 15"},
+		NewDocument("doc1", 1).Set("title", "goodbye world"),
+		NewDocument("doc2", 1).Set("title", "goodbye world")))
+	docs, total, err := c.Search(NewQuery("goodbye"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "testhashwriter:doc1", docs[0].Id)
+	assert.Equal(t, "10", docs[0].Properties["price"])
+
+	assert.Nil(t, w.IndexOptions(IndexingOptions{Replace: true}, NewDocument("doc1", 1).Set("title", "hello again")))
+	doc, err := c.Get("testhashwriter:doc1")
+	assert.Nil(t, err)
+	assert.Nil(t, doc.Properties["price"])
+}
+
+func TestJSONWriter(t *testing.T) {
+	c := createClient("testjson")
+	c.Drop()
+	def := NewIndexDefinition().SetIndexOn(JSONIndex).AddPrefix("testjson:")
+	sc := NewSchema(DefaultOptions).
+		AddField(NewTextField("$.title").SetAs("title")).
+		AddField(NewTagField("$.tags[*]").SetAs("tags")).
+		AddField(NewNumericField("$.price").SetAs("price"))
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(sc, def))
+	defer c.Drop()
+
+	w := NewJSONWriter(c, def)
+	assert.Nil(t, w.Index(NewJSONDocument("1", map[string]interface{}{"title": "hello world", "tags": []string{"a", "b"}, "price": 10}),
+		NewJSONDocument("2", map[string]interface{}{"title": "hello there", "tags": []string{"c"}, "price": 20})))
+
+	docs, total, err := c.Search(NewQuery("@title:hello @tags:{b}"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "testjson:1", docs[0].Id)
+	var product struct {
+		Title string   `json:"title"`
+		Tags  []string `json:"tags"`
+	}
+	assert.Nil(t, docs[0].DecodeJSON(&product))
+	assert.Equal(t, "hello world", product.Title)
+	assert.Equal(t, []string{"a", "b"}, product.Tags)
+}
+
+func TestClient_SearchKNN(t *testing.T) {
+	c := createClient("testknn")
+	c.Drop()
+	def := NewIndexDefinition().AddPrefix("testknn:")
+	sc := NewSchema(DefaultOptions).
+		AddField(NewTagField("category")).
+		AddField(NewVectorFieldOptions("vec", VectorFieldOptions{Algorithm: VectorHNSW, Type: VectorFloat32, Dim: 2, DistanceMetric: VectorL2}))
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(sc, def))
+	defer c.Drop()
+
+	w := NewHashWriter(c, def)
+	assert.Nil(t, w.Index(NewDocument("1", 1).Set("category", "a").SetVector("vec", []float32{0, 0}),
+		NewDocument("2", 1).Set("category", "a").SetVector("vec", []float32{3, 4}),
+		NewDocument("3", 1).Set("category", "b").SetVector("vec", []float32{0, 1})))
+
+	knn := NewKNNClause(2, "vec", EncodeFloat32Vector([]float32{0, 0}))
+	docs, total, err := c.Search(NewQuery("@category:{a}").SetKNN(knn).SetSortBy("__vec_score", true).SetReturnFields("category"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, "testknn:1", docs[0].Id)
+	assert.Equal(t, 0.0, docs[0].Distance)
+	assert.Equal(t, "testknn:2", docs[1].Id)
+	assert.Equal(t, 25.0, docs[1].Distance)
+}
+
+func TestClient_ExplainParams(t *testing.T) {
+	conn := &scriptedConn{replies: []interface{}{[]byte("INTERSECT {\n}\n"), []interface{}{}}}
+	c := NewClientFromPool(&scriptedPool{conn: conn}, "idx")
+	q := NewQuery("@title:$title").SetParam("title", "hello").SetDialect(3)
+
+	_, err := c.Explain(q)
+	assert.Nil(t, err)
+	_, _, err = c.SpellCheck(q, NewSpellCheckOptions(2))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"FT.EXPLAIN idx @title:$title PARAMS 2 title hello DIALECT 3",
+		"FT.SPELLCHECK idx @title:$title DIALECT 3 DISTANCE 2",
+	}, conn.commands)
+}
+
+func TestClient_AlterAddFieldsArgs(t *testing.T) {
+	conn := &scriptedConn{replies: []interface{}{"OK"}}
+	c := NewClientFromPool(&scriptedPool{conn: conn}, "idx")
+
+	assert.Nil(t, c.AlterAddFields(NewSortableTextField("title", 2), NewTagFieldOptions("$.tags", TagFieldOptions{Separator: ';'}).SetAs("tags")))
+	assert.Equal(t, []string{"FT.ALTER idx SCHEMA ADD title TEXT WEIGHT 2 SORTABLE $.tags AS tags TAG SEPARATOR ;"}, conn.commands)
+
+	assert.NotNil(t, c.AlterAddFields())
+	assert.NotNil(t, c.AlterAddFields(Field{Name: "price", Type: NumericField, Options: TextFieldOptions{}}))
+	assert.Equal(t, 1, len(conn.commands))
+}
+
+func TestIndexInfo_loadSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []interface{}
+		want   []Field
+	}{
+		{"name-type",
+			[]interface{}{
+				[]interface{}{"title", "type", "TEXT", "WEIGHT", "2", "SORTABLE"},
+				[]interface{}{"price", "type", "NUMERIC", "SORTABLE"},
+				[]interface{}{"tags", "type", "TAG", "SEPARATOR", ","},
+				[]interface{}{"location", "type", "GEO"},
+				[]interface{}{"vec", "type", "VECTOR"},
+			},
+			[]Field{
+				NewTextFieldOptions("title", TextFieldOptions{Weight: 2, Sortable: true}),
+				NewNumericFieldOptions("price", NumericFieldOptions{Sortable: true}),
+				NewTagField("tags"),
+				NewGeoFieldOptions("location", GeoFieldOptions{}),
+				{Name: "vec", Type: VectorField},
+			}},
+		{"identifier-attribute",
+			[]interface{}{
+				[]interface{}{"identifier", "$.title", "attribute", "title", "type", "TEXT", "WEIGHT", "1"},
+				[]interface{}{"identifier", "price", "attribute", "price", "type", "NUMERIC"},
+			},
+			[]Field{
+				NewTextFieldOptions("$.title", TextFieldOptions{Weight: 1}).SetAs("title"),
+				NewNumericFieldOptions("price", NumericFieldOptions{}),
+			}},
+		{"options",
+			[]interface{}{
+				[]interface{}{"title", "type", "TEXT", "WEIGHT", "0.5", "NOSTEM", "NOINDEX"},
+				[]interface{}{"tags", "type", "TAG", "SEPARATOR", ";", "SORTABLE"},
+				[]interface{}{"price", "type", "NUMERIC", "NOINDEX"},
+				[]interface{}{"location", "type", "GEO", "NOINDEX"},
+				[]interface{}{[]byte("body"), []byte("type"), []byte("TEXT"), []byte("WEIGHT"), int64(3)},
+			},
+			[]Field{
+				NewTextFieldOptions("title", TextFieldOptions{Weight: 0.5, NoStem: true, NoIndex: true}),
+				NewTagFieldOptions("tags", TagFieldOptions{Separator: ';', Sortable: true}),
+				NewNumericFieldOptions("price", NumericFieldOptions{NoIndex: true}),
+				NewGeoFieldOptions("location", GeoFieldOptions{NoIndex: true}),
+				NewTextFieldOptions("body", TextFieldOptions{Weight: 3}),
+			}},
+		{"vector",
+			[]interface{}{
+				[]interface{}{"identifier", "vec", "attribute", "vec", "type", "VECTOR", "algorithm", "HNSW",
+					"data_type", "FLOAT32", "dim", int64(4), "distance_metric", "COSINE", "M", int64(16)},
+				[]interface{}{"identifier", "flat", "attribute", "flat", "type", "VECTOR"},
+			},
+			[]Field{
+				NewVectorFieldOptions("vec", VectorFieldOptions{Algorithm: VectorHNSW, Type: VectorFloat32, Dim: 4, DistanceMetric: VectorCosine, M: 16}),
+				{Name: "flat", Type: VectorField},
+			}},
+		{"invalid",
+			[]interface{}{
+				[]interface{}{"title", "type"},
+				[]interface{}{"title", "type", "UNKNOWN"},
+				"title",
+			},
+			[]Field{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &IndexInfo{}
+			info.loadSchema(tt.fields, nil)
+			if !reflect.DeepEqual(info.Schema.Fields, tt.want) {
+				t.Errorf("loadSchema() = %v, want %v", info.Schema.Fields, tt.want)
+			}
+		})
+	}
+}
+
+func Test_loadIndexInfo(t *testing.T) {
+	res := []interface{}{
+		"index_name", []byte("idx"),
+		"index_options", []interface{}{"NOFREQS", "NOHL"},
+		"index_definition", []interface{}{
+			"key_type", []byte("HASH"),
+			"prefixes", []interface{}{[]byte("product:")},
+			"filter", []byte("@price>0"),
+			"default_language", []byte("english"),
+			"language_field", []byte("__language"),
+			"default_score", []byte("1"),
+			"score_field", []byte("rank"),
+			"payload_field", []byte("__payload"),
+		},
+		"attributes", []interface{}{
+			[]interface{}{"identifier", "title", "attribute", "title", "type", "TEXT", "WEIGHT", "2", "NOSTEM"},
+			[]interface{}{"identifier", "tags", "attribute", "tags", "type", "TAG", "SEPARATOR", "|"},
+		},
+		"num_docs", int64(3),
+		"indexing", int64(1),
+		"percent_indexed", []byte("0.5"),
+		"hash_indexing_failures", int64(2),
+		"stopwords_list", []interface{}{[]byte("a"), []byte("the")},
+		"gc_stats", []interface{}{
+			"bytes_collected", []byte("10"),
+			"total_cycles", []byte("4"),
+			"average_cycle_time_ms", []byte("1.5"),
+		},
+		"cursor_stats", []interface{}{
+			"global_idle", int64(1),
+			"global_total", int64(2),
+			"index_capacity", int64(128),
+			"index_total", int64(1),
+		},
+	}
+	info := loadIndexInfo(res)
+	assert.Equal(t, "idx", info.Name)
+	assert.Equal(t, uint64(3), info.DocCount)
+	assert.Equal(t, uint64(1), info.Indexing)
+	assert.Equal(t, 0.5, info.PercentIndexed)
+	assert.Equal(t, uint64(2), info.HashIndexingFailures)
+	assert.Equal(t, GCStats{BytesCollected: 10, TotalCycles: 4, AverageCycleTimeMs: 1.5}, info.GCStats)
+	assert.Equal(t, CursorStats{GlobalIdle: 1, GlobalTotal: 2, IndexCapacity: 128, IndexTotal: 1}, info.CursorStats)
+
+	// the parsed schema creates the same index
+	want := NewSchema(Options{NoFrequencies: true, NoHighlights: true, Stopwords: []string{"a", "the"}}).
+		AddField(NewTextFieldOptions("title", TextFieldOptions{Weight: 2, NoStem: true})).
+		AddField(NewTagFieldOptions("tags", TagFieldOptions{Separator: '|'})).
+		SetIndexDefinition(NewIndexDefinition().AddPrefix("product:").SetFilterExpression("@price>0").SetScoreField("rank"))
+	assert.Equal(t, want, &info.Schema)
+	got, err := SerializeSchema(&info.Schema, redis.Args{"idx"})
+	assert.Nil(t, err)
+	wantArgs, err := SerializeSchema(want, redis.Args{"idx"})
+	assert.Nil(t, err)
+	assert.Equal(t, wantArgs, got)
+}
+
+func TestClient_AlterAddFields(t *testing.T) {
+	c := createClient("testalter")
+	c.Drop()
+	sc := NewSchema(DefaultOptions).AddField(NewTextField("title"))
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(sc, NewIndexDefinition().AddPrefix("testalter:")))
+	defer c.Drop()
+
+	desired := NewSchema(DefaultOptions).
+		AddField(NewTextField("title")).
+		AddField(NewSortableNumericField("price"))
+	info, err := c.Info()
+	assert.Nil(t, err)
+	diff := desired.Diff(&info.Schema)
+	assert.False(t, diff.RequiresRebuild())
+	assert.Equal(t, []Field{NewSortableNumericField("price")}, diff.Added)
+	assert.Nil(t, c.AlterAddFields(diff.Added...))
+
+	info, err = c.Info()
+	assert.Nil(t, err)
+	diff = desired.Diff(&info.Schema)
+	assert.Equal(t, SchemaDiff{}, diff)
+
+	diff = NewSchema(DefaultOptions).AddField(NewTagField("title")).Diff(&info.Schema)
+	assert.True(t, diff.RequiresRebuild())
+	assert.Equal(t, 1, len(diff.Removed))
+}
+
+func TestMigrate(t *testing.T) {
+	c := createClient("testmigrate")
+	NewClientFromPool(c.pool, "testmigrate_v1").DropIndex(true)
+	NewClientFromPool(c.pool, "testmigrate_v2").DropIndex(true)
+	c.AliasDel("testmigrate")
+
+	definition := NewIndexDefinition().AddPrefix("testmigrate:")
+	v1 := NewSchema(DefaultOptions).AddField(NewTextField("title")).SetIndexDefinition(definition)
+	name, err := c.Migrate(NewMigration(v1))
+	assert.Nil(t, err)
+	assert.Equal(t, "testmigrate_v1", name)
+
+	w := NewHashWriter(c, definition)
+	assert.Nil(t, w.IndexOptions(IndexingOptions{Replace: true}, NewDocument("1", 1).Set("title", "hello").Set("price", 10)))
+
+	// the new index is backfilled with the existing hashes
+	v2 := NewSchema(DefaultOptions).AddField(NewTextField("title")).AddField(NewNumericField("price")).SetIndexDefinition(definition)
+	name, err = c.Migrate(NewMigration(v2).SetDropOld(true))
+	assert.Nil(t, err)
+	assert.Equal(t, "testmigrate_v2", name)
+	defer NewClientFromPool(c.pool, "testmigrate_v2").DropIndex(true)
+
+	docs, total, err := c.Search(NewQuery("@price:[5 20]"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "testmigrate:1", docs[0].Id)
+
+	_, err = NewClientFromPool(c.pool, "testmigrate_v1").Info()
+	assert.NotNil(t, err)
+}
+
+func TestClient_InfoRoundTrip(t *testing.T) {
+	c := createClient("testinforoundtrip")
+	c.Drop()
+	sc := NewSchema(Options{NoFrequencies: true, Stopwords: []string{"a", "the"}, Temporary: 3600, SkipInitialScan: true}).
+		AddField(NewTextFieldOptions("title", TextFieldOptions{Weight: 2, NoStem: true, Sortable: true})).
+		AddField(NewTagFieldOptions("tags", TagFieldOptions{Separator: ';'})).
+		AddField(NewSortableNumericField("price")).
+		AddField(NewGeoField("location")).
+		SetIndexDefinition(NewIndexDefinition().AddPrefix("testinforoundtrip:").SetScoreField("rank"))
+	assert.Nil(t, c.CreateIndex(sc))
+
+	info, err := c.Info()
+	assert.Nil(t, err)
+	// FT.INFO does not report TEMPORARY and SKIPINITIALSCAN, they have to be set again
+	assert.Equal(t, 0, info.Schema.Options.Temporary)
+	assert.False(t, info.Schema.Options.SkipInitialScan)
+	schema := info.Schema
+	schema.Options.Temporary = sc.Options.Temporary
+	schema.Options.SkipInitialScan = sc.Options.SkipInitialScan
+	assert.Nil(t, c.DropIndex(false))
+	assert.Nil(t, c.CreateIndex(&schema))
+	defer c.DropIndex(true)
+
+	recreated, err := c.Info()
+	assert.Nil(t, err)
+	assert.Equal(t, info.Schema, recreated.Schema)
+	assert.Equal(t, SchemaDiff{}, sc.Diff(&recreated.Schema))
+}
+
+func TestClient_SynUpdate(t *testing.T) {
+	c := createClient("testsynonyms")
+	c.Drop()
+	sc := NewSchema(DefaultOptions).AddField(NewTextField("title"))
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(sc, NewIndexDefinition().AddPrefix("testsynonyms:")))
+	defer c.DropIndex(true)
+
+	conn := c.pool.Get()
+	defer conn.Close()
+	_, err := conn.Do("HSET", "testsynonyms:1", "title", "comfortable sofa")
+	assert.Nil(t, err)
+
+	assert.Nil(t, c.SynUpdate("couch", false, "couch", "sofa"))
+	groups, err := c.SynDump()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string][]string{"couch": {"couch"}, "sofa": {"couch"}}, groups)
+
+	_, total, err := c.Search(NewQuery("couch"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+
+	updated, err := c.SyncSynonyms(strings.NewReader("couch, sofa, divan\n"), false)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, updated)
+	updated, err = c.SyncSynonyms(strings.NewReader("couch, sofa, divan\n"), false)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, updated)
+}
+
+func TestClient_TagValsChecksSchema(t *testing.T) {
+	info := []interface{}{
+		"index_name", []byte("idx"),
+		"attributes", []interface{}{
+			[]interface{}{"identifier", "$.tags", "attribute", "tags", "type", "TAG", "SEPARATOR", ","},
+			[]interface{}{"identifier", "title", "attribute", "title", "type", "TEXT", "WEIGHT", "1"},
+		},
+	}
+	tests := []struct {
+		name    string
+		field   string
+		want    []string
+		wantErr error
+	}{
+		{"tag", "tags", []string{"blue", "red"}, nil},
+		{"prefixed", "@tags", []string{"blue", "red"}, nil},
+		{"text", "title", nil, &FieldTypeError{Field: "title", Expected: TagField, Actual: TextField}},
+		{"missing", "color", nil, &FieldTypeError{Field: "color", Expected: TagField, Missing: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &scriptedConn{replies: []interface{}{info, []interface{}{[]byte("blue"), []byte("red")}}}
+			c := NewClientFromPool(&scriptedPool{conn: conn}, "idx")
+			got, err := c.TagVals(tt.field)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+			if tt.wantErr == nil {
+				assert.Equal(t, []string{"FT.INFO idx", "FT.TAGVALS idx tags"}, conn.commands)
+			}
+		})
+	}
+	err := &FieldTypeError{Field: "title", Expected: TagField, Actual: TextField}
+	assert.Equal(t, "field title is a TEXT field, a TAG field is required", err.Error())
+}
+
+func TestClient_TagVals(t *testing.T) {
+	c := createClient("testtagvals")
+	c.Drop()
+	sc := NewSchema(DefaultOptions).AddField(NewTextField("title")).AddField(NewTagField("color"))
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(sc, NewIndexDefinition().AddPrefix("testtagvals:")))
+	defer c.DropIndex(true)
+
+	conn := c.pool.Get()
+	defer conn.Close()
+	_, err := conn.Do("HSET", "testtagvals:1", "title", "shirt", "color", "Red,blue")
+	assert.Nil(t, err)
+	_, err = conn.Do("HSET", "testtagvals:2", "title", "hat", "color", "red")
+	assert.Nil(t, err)
+
+	values, err := c.TagVals("color")
+	assert.Nil(t, err)
+	sort.Strings(values)
+	assert.Equal(t, []string{"blue", "red"}, values)
+
+	_, err = c.TagVals("title")
+	_, ok := err.(*FieldTypeError)
+	assert.True(t, ok)
+}
+
+func TestClient_ConfigSet(t *testing.T) {
+	c := createClient("testconfig")
+	before, err := c.ConfigGet("TIMEOUT")
+	assert.Nil(t, err)
+	defer c.ConfigSet("TIMEOUT", before["TIMEOUT"])
+
+	assert.Nil(t, c.ConfigSet("TIMEOUT", "1234"))
+	config, err := c.Config()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1234), config.Timeout)
+	assert.NotNil(t, c.ConfigSet("NOT_AN_OPTION", "1"))
+}
+
+func TestListIndexes(t *testing.T) {
+	c := createClient("testlistindexes")
+	c.Drop()
+	sc := NewSchema(DefaultOptions).AddField(NewTextField("title"))
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(sc, NewIndexDefinition().AddPrefix("testlistindexes:")))
+	defer c.DropIndex(true)
+
+	names, err := ListIndexes(c.pool)
+	assert.Nil(t, err)
+	assert.Contains(t, names, "testlistindexes")
+
+	infos, err := InfoAll(c.pool, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, len(names), len(infos))
+}
+
+func TestClient_ProfileSearchIntegration(t *testing.T) {
+	c := createClient("testprofile")
+	c.Drop()
+	sc := NewSchema(DefaultOptions).AddField(NewTextField("title"))
+	assert.Nil(t, c.CreateIndexWithIndexDefinition(sc, NewIndexDefinition().AddPrefix("testprofile:")))
+	defer c.DropIndex(true)
+
+	conn := c.pool.Get()
+	defer conn.Close()
+	_, err := conn.Do("HSET", "testprofile:1", "title", "hello world")
+	assert.Nil(t, err)
+
+	docs, total, profile, err := c.ProfileSearch(NewQuery("hello world"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "testprofile:1", docs[0].Id)
+	assert.NotNil(t, profile.Iterators)
+	assert.True(t, len(profile.ResultProcessors) > 0)
+	assert.Contains(t, profile.String(), "Iterators:")
+}
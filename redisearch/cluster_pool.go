@@ -0,0 +1,561 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	// clusterSlots is the number of hash slots of a Redis Cluster
+	clusterSlots = 16384
+
+	// maxClusterRedirects is the number of MOVED and ASK redirects followed by a command
+	maxClusterRedirects = 5
+)
+
+// errClusterPoolClosed is the error of the connections of a closed ClusterPool
+var errClusterPoolClosed = errors.New("ClusterPool: pool closed")
+
+// ClusterPool is a ConnPool for sharded deployments on Redis Cluster. The connections it returns
+// route every command to the right node: commands on keys, i.e. the HSET of HashWriter or FT.SUGADD on the suggestion key,
+// are sent to the master owning the slot of the key, while index commands such as FT.SEARCH are sent
+// to any master, as expected by coordinator based deployments. A connection sends all of them to the same master,
+// so that the cursors of FT.AGGREGATE are read and deleted on the node holding them. SCRIPT commands are sent to all the masters.
+// The slots are discovered with CLUSTER SLOTS on first use, and updated following MOVED redirects
+type ClusterPool struct {
+	sync.Mutex
+	seeds   []string
+	pools   map[string]ConnPool
+	slots   []string
+	masters []string
+
+	// discovered is set once the slots are loaded on first use, even if it failed
+	discovered bool
+
+	closed bool
+
+	// newPool creates the pool of the connections to a node
+	newPool func(addr string) ConnPool
+}
+
+// NewClusterPool creates a pool for the cluster of the seed nodes, given as host:port.
+// The connections to the seed nodes and to the discovered ones are configured with opts, i.e. WithAuth or WithTLSConfig
+func NewClusterPool(seeds []string, opts ...ClientOption) *ClusterPool {
+	o := newClientOptions(opts)
+	return &ClusterPool{
+		seeds: seeds,
+		pools: map[string]ConnPool{},
+		newPool: func(addr string) ConnPool {
+			return &SingleHostPool{o.newPool(addr)}
+		},
+	}
+}
+
+// Get returns a connection routing the commands to the nodes of the cluster.
+// It holds a connection to every node it sends commands to, until it is closed.
+// The connections of a closed pool fail all their commands
+func (p *ClusterPool) Get() redis.Conn {
+	p.Lock()
+	defer p.Unlock()
+	if p.closed {
+		return errorConn{errClusterPoolClosed}
+	}
+	return &clusterConn{pool: p, conns: map[string]redis.Conn{}}
+}
+
+// Refresh reloads the slots of the masters of the cluster with CLUSTER SLOTS
+func (p *ClusterPool) Refresh() error {
+	p.Lock()
+	nodes := append(append([]string{}, p.masters...), p.seeds...)
+	p.Unlock()
+
+	err := errors.New("ClusterPool: no nodes")
+	for _, addr := range nodes {
+		var slots, masters []string
+		conn := p.nodePool(addr).Get()
+		reply, e := conn.Do("CLUSTER", "SLOTS")
+		conn.Close()
+		if e == nil {
+			slots, masters, e = parseClusterSlots(reply, addr)
+		}
+		if e != nil {
+			err = e
+			continue
+		}
+		p.Lock()
+		p.slots, p.masters = slots, masters
+		p.Unlock()
+		return nil
+	}
+	return err
+}
+
+// Close closes the pools of the nodes. The connections in use fail their next commands
+func (p *ClusterPool) Close() error {
+	p.Lock()
+	defer p.Unlock()
+	p.closed = true
+	var err error
+	for addr, pool := range p.pools {
+		if closer, ok := pool.(interface {
+			Close() error
+		}); ok {
+			if e := closer.Close(); e != nil && err == nil {
+				err = e
+			}
+		}
+		delete(p.pools, addr)
+	}
+	return err
+}
+
+// nodePool returns the pool of a node, creating it on first use
+func (p *ClusterPool) nodePool(addr string) ConnPool {
+	p.Lock()
+	defer p.Unlock()
+	if p.closed {
+		return errorPool{errClusterPoolClosed}
+	}
+	pool, found := p.pools[addr]
+	if !found {
+		pool = p.newPool(addr)
+		p.pools[addr] = pool
+	}
+	return pool
+}
+
+// ensureSlots loads the slots on first use. If it fails, commands are sent
+// to the seed nodes and follow the redirects, until Refresh succeeds
+func (p *ClusterPool) ensureSlots() {
+	p.Lock()
+	discovered := p.discovered
+	p.discovered = true
+	p.Unlock()
+	if !discovered {
+		p.Refresh()
+	}
+}
+
+// keyNode returns the address of the master owning the key of a command, "" if it has no key or its slot is unknown
+func (p *ClusterPool) keyNode(commandName string, args []interface{}) string {
+	p.ensureSlots()
+	p.Lock()
+	defer p.Unlock()
+	if pos := commandKeyPosition(commandName, args); pos >= 0 && p.slots != nil {
+		return p.slots[keySlot(argString(args[pos]))]
+	}
+	return ""
+}
+
+// anyNode returns the address of a random master, or of a random seed node if unknown
+func (p *ClusterPool) anyNode() string {
+	p.ensureSlots()
+	p.Lock()
+	defer p.Unlock()
+	if len(p.masters) > 0 {
+		return p.masters[rand.Intn(len(p.masters))]
+	}
+	return p.seeds[rand.Intn(len(p.seeds))]
+}
+
+// allMasters returns the addresses of the masters, or of the seed nodes if unknown
+func (p *ClusterPool) allMasters() []string {
+	p.ensureSlots()
+	p.Lock()
+	defer p.Unlock()
+	if len(p.masters) > 0 {
+		return append([]string{}, p.masters...)
+	}
+	return append([]string{}, p.seeds...)
+}
+
+// moved records the new owner of a slot after a MOVED redirect
+func (p *ClusterPool) moved(slot int, addr string) {
+	p.Lock()
+	defer p.Unlock()
+	if slot < 0 || slot >= clusterSlots {
+		return
+	}
+	if p.slots == nil {
+		p.slots = make([]string, clusterSlots)
+	}
+	p.slots[slot] = addr
+}
+
+// parseClusterSlots parses the reply of CLUSTER SLOTS, returning the address of the master of each slot,
+// and the addresses of all the masters. Nodes without a host are on the one of addr, the node that replied
+func parseClusterSlots(reply interface{}, addr string) (slots []string, masters []string, err error) {
+	ranges, err := redis.Values(reply, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defaultHost, _, _ := net.SplitHostPort(addr)
+	slots = make([]string, clusterSlots)
+	for _, r := range ranges {
+		values, err := redis.Values(r, nil)
+		if err != nil || len(values) < 3 {
+			return nil, nil, fmt.Errorf("ClusterPool: invalid slots range %v", r)
+		}
+		start, err := redis.Int(values[0], nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		end, err := redis.Int(values[1], nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		node, err := redis.Values(values[2], nil)
+		if err != nil || len(node) < 2 || start < 0 || end >= clusterSlots || start > end {
+			return nil, nil, fmt.Errorf("ClusterPool: invalid slots range %v", r)
+		}
+		host, _ := redis.String(node[0], nil)
+		if host == "" {
+			host = defaultHost
+		}
+		port, err := redis.Int(node[1], nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		master := net.JoinHostPort(host, strconv.Itoa(port))
+		if sliceIndex(masters, master) == -1 {
+			masters = append(masters, master)
+		}
+		for slot := start; slot <= end; slot++ {
+			slots[slot] = master
+		}
+	}
+	return slots, masters, nil
+}
+
+// commandKeyPosition returns the position of the key among the arguments of a command, -1 if it has none.
+// FT.* commands address indexes, except the FT.SUG* ones on suggestion dictionaries
+func commandKeyPosition(commandName string, args []interface{}) int {
+	name := strings.ToUpper(commandName)
+	switch {
+	case name == "EVAL" || name == "EVALSHA":
+		if len(args) > 2 {
+			if numKeys, _ := strconv.Atoi(argString(args[1])); numKeys > 0 {
+				return 2
+			}
+		}
+		return -1
+	case strings.HasPrefix(name, "FT.SUG"):
+		if len(args) > 0 {
+			return 0
+		}
+		return -1
+	case strings.HasPrefix(name, "FT."), len(args) == 0:
+		return -1
+	}
+	switch name {
+	case "PING", "ECHO", "INFO", "CLUSTER", "SCRIPT", "ASKING", "READONLY", "READWRITE", "AUTH", "SELECT",
+		"CONFIG", "COMMAND", "CLIENT", "TIME", "DBSIZE", "FLUSHALL", "FLUSHDB", "KEYS", "SCAN", "MODULE":
+		return -1
+	}
+	return 0
+}
+
+// argString returns an argument of a command as a string
+func argString(arg interface{}) string {
+	switch v := arg.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return fmt.Sprint(arg)
+}
+
+// keySlot returns the hash slot of a key, hashing only its hash tag if it has one, i.e. {user1}:cart
+func keySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key)) % clusterSlots
+}
+
+// crc16 is the CRC-16/XMODEM checksum used by Redis Cluster
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// parseRedirect parses MOVED and ASK errors, i.e. MOVED 3999 127.0.0.1:6381
+func parseRedirect(err error) (slot int, addr string, ask bool, ok bool) {
+	rerr, isRedisErr := err.(redis.Error)
+	if !isRedisErr {
+		return
+	}
+	parts := strings.Fields(string(rerr))
+	if len(parts) != 3 || (parts[0] != "MOVED" && parts[0] != "ASK") {
+		return
+	}
+	slot, e := strconv.Atoi(parts[1])
+	if e != nil {
+		return
+	}
+	return slot, parts[2], parts[0] == "ASK", true
+}
+
+// clusterConn is a connection of a ClusterPool, routing the commands to the nodes of the cluster
+type clusterConn struct {
+	pool    *ClusterPool
+	conns   map[string]redis.Conn
+	pending []*clusterCommand
+	closed  bool
+
+	// node is the master the commands without a key are sent to, picked on first use
+	node string
+}
+
+// clusterCommand is a pipelined command waiting for its reply
+type clusterCommand struct {
+	addr        string
+	commandName string
+	args        []interface{}
+
+	// done is set for the commands run right away, with their reply
+	done  bool
+	reply interface{}
+	err   error
+}
+
+// route returns the address of the node a command is sent to
+func (c *clusterConn) route(commandName string, args []interface{}) string {
+	if addr := c.pool.keyNode(commandName, args); addr != "" {
+		return addr
+	}
+	if c.node == "" {
+		c.node = c.pool.anyNode()
+	}
+	return c.node
+}
+
+// conn returns the connection to a node, getting it from its pool on first use
+func (c *clusterConn) conn(addr string) redis.Conn {
+	conn, found := c.conns[addr]
+	if !found {
+		conn = c.pool.nodePool(addr).Get()
+		c.conns[addr] = conn
+	}
+	return conn
+}
+
+func (c *clusterConn) Do(commandName string, args ...interface{}) (interface{}, error) {
+	return c.DoContext(context.Background(), commandName, args...)
+}
+
+// DoContext runs a command, waiting for the replies of the nodes with their context-aware methods
+func (c *clusterConn) DoContext(ctx context.Context, commandName string, args ...interface{}) (interface{}, error) {
+	if c.closed {
+		return nil, errors.New("redisearch: connection closed")
+	}
+	if commandName == "" {
+		// as for redigo connections, flush the pipeline and return the last reply
+		if err := c.Flush(); err != nil {
+			return nil, err
+		}
+		var reply interface{}
+		var err error
+		for len(c.pending) > 0 {
+			reply, err = c.ReceiveContext(ctx)
+		}
+		return reply, err
+	}
+	if strings.EqualFold(commandName, "SCRIPT") {
+		return c.broadcast(ctx, commandName, args)
+	}
+	return c.do(ctx, c.route(commandName, args), commandName, args, false)
+}
+
+// syncConn returns the connection to a node for a command waiting for its reply, and whether it must be closed
+// after it: the held connection cannot be used while replies of pipelined commands are pending on it
+func (c *clusterConn) syncConn(addr string) (redis.Conn, bool) {
+	for _, cmd := range c.pending {
+		if !cmd.done && cmd.addr == addr {
+			return c.pool.nodePool(addr).Get(), true
+		}
+	}
+	return c.conn(addr), false
+}
+
+// do runs a command on a node, following the MOVED and ASK redirects
+func (c *clusterConn) do(ctx context.Context, addr, commandName string, args []interface{}, asking bool) (reply interface{}, err error) {
+	for redirects := 0; ; redirects++ {
+		conn, temporary := c.syncConn(addr)
+		err = nil
+		if asking {
+			_, err = doContext(ctx, conn, "ASKING")
+		}
+		if err == nil {
+			reply, err = doContext(ctx, conn, commandName, args...)
+		}
+		if temporary {
+			conn.Close()
+		}
+		slot, target, ask, ok := parseRedirect(err)
+		if !ok || redirects == maxClusterRedirects {
+			return
+		}
+		if !ask {
+			c.pool.moved(slot, target)
+		}
+		addr, asking = target, ask
+	}
+}
+
+// broadcast runs a command on all the masters, returning the reply of the first one, or the first error
+func (c *clusterConn) broadcast(ctx context.Context, commandName string, args []interface{}) (reply interface{}, err error) {
+	for ii, addr := range c.pool.allMasters() {
+		conn, temporary := c.syncConn(addr)
+		r, e := doContext(ctx, conn, commandName, args...)
+		if temporary {
+			conn.Close()
+		}
+		if ii == 0 {
+			reply = r
+		}
+		if e != nil && err == nil {
+			err = e
+		}
+	}
+	return
+}
+
+func (c *clusterConn) Send(commandName string, args ...interface{}) error {
+	if c.closed {
+		return errors.New("redisearch: connection closed")
+	}
+	if strings.EqualFold(commandName, "SCRIPT") {
+		reply, err := c.broadcast(context.Background(), commandName, args)
+		c.pending = append(c.pending, &clusterCommand{done: true, reply: reply, err: err})
+		return nil
+	}
+	addr := c.route(commandName, args)
+	if err := c.conn(addr).Send(commandName, args...); err != nil {
+		return err
+	}
+	c.pending = append(c.pending, &clusterCommand{addr: addr, commandName: commandName, args: args})
+	return nil
+}
+
+func (c *clusterConn) Flush() error {
+	for _, conn := range c.conns {
+		if err := conn.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Receive returns the reply of the oldest pipelined command. Redirected commands are run again on the right node
+func (c *clusterConn) Receive() (interface{}, error) {
+	return c.ReceiveContext(context.Background())
+}
+
+// ReceiveContext is the context-aware version of Receive
+func (c *clusterConn) ReceiveContext(ctx context.Context) (interface{}, error) {
+	if len(c.pending) == 0 {
+		return nil, errors.New("redisearch: no pending replies")
+	}
+	cmd := c.pending[0]
+	c.pending = c.pending[1:]
+	if cmd.done {
+		return cmd.reply, cmd.err
+	}
+	reply, err := receiveContext(ctx, c.conns[cmd.addr])
+	if slot, target, ask, ok := parseRedirect(err); ok {
+		if !ask {
+			c.pool.moved(slot, target)
+		}
+		return c.do(ctx, target, cmd.commandName, cmd.args, ask)
+	}
+	return reply, err
+}
+
+// Err returns the error of the first broken node connection
+func (c *clusterConn) Err() error {
+	if c.closed {
+		return errors.New("redisearch: connection closed")
+	}
+	for _, conn := range c.conns {
+		if err := conn.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close returns the node connections to their pools
+func (c *clusterConn) Close() error {
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	var err error
+	for _, conn := range c.conns {
+		if e := conn.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	c.conns = nil
+	return err
+}
+
+// errorConn is a connection failing all its commands with err
+type errorConn struct {
+	err error
+}
+
+func (c errorConn) Do(commandName string, args ...interface{}) (interface{}, error) {
+	return nil, c.err
+}
+
+func (c errorConn) Send(commandName string, args ...interface{}) error {
+	return c.err
+}
+
+func (c errorConn) Flush() error {
+	return c.err
+}
+
+func (c errorConn) Receive() (interface{}, error) {
+	return nil, c.err
+}
+
+func (c errorConn) Err() error {
+	return c.err
+}
+
+func (c errorConn) Close() error {
+	return nil
+}
+
+// errorPool is a pool of connections failing with err
+type errorPool struct {
+	err error
+}
+
+func (p errorPool) Get() redis.Conn {
+	return errorConn{p.err}
+}
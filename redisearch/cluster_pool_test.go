@@ -0,0 +1,297 @@
+package redisearch
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_keySlot(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		want int
+	}{
+		{"plain", "foo", 12182},
+		{"check-value", "123456789", 0x31C3},
+		{"hash-tag", "{user1000}.following", keySlot("user1000")},
+		{"first-tag", "{user1000}.{other}", keySlot("user1000")},
+		{"empty-tag", "foo{}{bar}", int(crc16("foo{}{bar}")) % clusterSlots},
+		{"unclosed-tag", "foo{bar", int(crc16("foo{bar")) % clusterSlots},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keySlot(tt.key); got != tt.want {
+				t.Errorf("keySlot() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_commandKeyPosition(t *testing.T) {
+	tests := []struct {
+		name        string
+		commandName string
+		args        []interface{}
+		want        int
+	}{
+		{"hset", "HSET", []interface{}{"doc1", "title", "hello"}, 0},
+		{"json", "JSON.SET", []interface{}{"doc1", "$", "{}"}, 0},
+		{"evalsha", "EVALSHA", []interface{}{"sha", 1, "doc1", "add"}, 2},
+		{"eval-no-keys", "EVAL", []interface{}{"return 1", "0"}, -1},
+		{"sugadd", "FT.SUGADD", []interface{}{"sug", "hello", 1}, 0},
+		{"search", "FT.SEARCH", []interface{}{"idx", "hello"}, -1},
+		{"script", "SCRIPT", []interface{}{"LOAD", "return 1"}, -1},
+		{"no-args", "PING", nil, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commandKeyPosition(tt.commandName, tt.args); got != tt.want {
+				t.Errorf("commandKeyPosition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseClusterSlots(t *testing.T) {
+	reply := []interface{}{
+		[]interface{}{int64(0), int64(5460), []interface{}{[]byte("10.0.0.1"), int64(7000), []byte("id1")},
+			[]interface{}{[]byte("10.0.0.4"), int64(7003), []byte("id4")}},
+		[]interface{}{int64(5461), int64(16383), []interface{}{[]byte(""), int64(7001), []byte("id2")}},
+	}
+	slots, masters, err := parseClusterSlots(reply, "10.0.0.9:7005")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"10.0.0.1:7000", "10.0.0.9:7001"}, masters)
+	assert.Equal(t, "10.0.0.1:7000", slots[0])
+	assert.Equal(t, "10.0.0.1:7000", slots[5460])
+	assert.Equal(t, "10.0.0.9:7001", slots[5461])
+	assert.Equal(t, "10.0.0.9:7001", slots[16383])
+
+	_, _, err = parseClusterSlots([]interface{}{[]interface{}{int64(0), int64(16384), []interface{}{[]byte("a"), int64(1)}}}, "")
+	assert.NotNil(t, err)
+	_, _, err = parseClusterSlots(redis.Error("ERR This instance has cluster support disabled"), "")
+	assert.NotNil(t, err)
+}
+
+// testClusterPool returns a cluster pool of two masters: b owns the slot of key, a all the others
+func testClusterPool(key string, a, b *scriptedConn) *ClusterPool {
+	slot := int64(keySlot(key))
+	nodeA := []interface{}{[]byte("10.0.0.1"), int64(7000)}
+	nodeB := []interface{}{[]byte("10.0.0.2"), int64(7000)}
+	slots := []interface{}{
+		[]interface{}{int64(0), slot - 1, nodeA},
+		[]interface{}{slot, slot, nodeB},
+		[]interface{}{slot + 1, int64(clusterSlots - 1), nodeA},
+	}
+	a.replies = append([]interface{}{slots}, a.replies...)
+	p := NewClusterPool([]string{"10.0.0.1:7000"})
+	p.newPool = func(addr string) ConnPool {
+		if addr == "10.0.0.2:7000" {
+			return &scriptedPool{conn: b}
+		}
+		return &scriptedPool{conn: a}
+	}
+	return p
+}
+
+func TestClusterPool_route(t *testing.T) {
+	a := &scriptedConn{replies: []interface{}{int64(1), "OK", "OK"}}
+	b := &scriptedConn{replies: []interface{}{int64(1), "OK"}}
+	p := testClusterPool("foo", a, b)
+
+	conn := p.Get()
+	_, err := conn.Do("HSET", "foo", "title", "hello")
+	assert.Nil(t, err)
+	_, err = conn.Do("HSET", "bar", "title", "hello")
+	assert.Nil(t, err)
+	// SCRIPT is run on every master
+	_, err = conn.Do("SCRIPT", "LOAD", "return 1")
+	assert.Nil(t, err)
+	_, err = conn.Do("FT.SUGADD", "bar", "hello", 1)
+	assert.Nil(t, err)
+	assert.Nil(t, conn.Close())
+
+	assert.Equal(t, []string{"CLUSTER SLOTS", "HSET bar title hello", "SCRIPT LOAD return 1", "FT.SUGADD bar hello 1"}, a.commands)
+	assert.Equal(t, []string{"HSET foo title hello", "SCRIPT LOAD return 1"}, b.commands)
+	assert.Equal(t, 2, a.closed)
+	assert.Equal(t, 1, b.closed)
+
+	// index commands go to any master
+	_, err = p.Get().Do("FT.SEARCH", "idx", "hello")
+	assert.NotNil(t, err)
+	assert.Equal(t, 7, len(a.commands)+len(b.commands))
+}
+
+func TestClusterPool_Autocompleter(t *testing.T) {
+	a := &scriptedConn{}
+	b := &scriptedConn{replies: []interface{}{int64(1), int64(2), int64(2)}}
+	p := testClusterPool("dict", a, b)
+
+	ac := NewAutocompleterFromPool(p, "dict")
+	assert.Nil(t, ac.AddTerms(Suggestion{Term: "hello", Score: 1}, Suggestion{Term: "world", Score: 1}))
+	n, err := ac.Length()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), n)
+
+	assert.Equal(t, []string{"CLUSTER SLOTS"}, a.commands)
+	assert.Equal(t, []string{"FT.SUGADD dict hello 1", "FT.SUGADD dict world 1", "FT.SUGLEN dict"}, b.commands)
+}
+
+func TestClusterPool_cursor(t *testing.T) {
+	// the node of the index commands is random, but the same for all the commands of a connection
+	for ii := 0; ii < 8; ii++ {
+		a := &scriptedConn{replies: []interface{}{cursorPage(42, "sony"), cursorPage(42, "sega"), "OK"}}
+		b := &scriptedConn{replies: []interface{}{cursorPage(42, "sony"), cursorPage(42, "sega"), "OK"}}
+		p := testClusterPool("foo", a, b)
+		c := NewClientFromPool(p, "idx")
+
+		it := c.AggregateIter(NewAggregateQuery())
+		assert.True(t, it.Next())
+		assert.True(t, it.Next())
+		assert.Nil(t, it.Close())
+		assert.Nil(t, it.Err())
+
+		want := []string{"FT.AGGREGATE idx * WITHCURSOR", "FT.CURSOR READ idx 42", "FT.CURSOR DEL idx 42"}
+		if len(b.commands) == 0 {
+			assert.Equal(t, append([]string{"CLUSTER SLOTS"}, want...), a.commands)
+		} else {
+			assert.Equal(t, []string{"CLUSTER SLOTS"}, a.commands)
+			assert.Equal(t, want, b.commands)
+		}
+	}
+}
+
+func TestClusterPool_redirects(t *testing.T) {
+	a := &scriptedConn{replies: []interface{}{"OK", "OK", "OK", int64(1)}}
+	b := &scriptedConn{replies: []interface{}{
+		redis.Error("MOVED " + strconv.Itoa(keySlot("foo")) + " 10.0.0.1:7000"),
+		redis.Error("ASK " + strconv.Itoa(keySlot("baz")) + " 10.0.0.1:7000"),
+	}}
+	p := testClusterPool("foo", a, b)
+	conn := p.Get()
+
+	// MOVED updates the slot
+	reply, err := conn.Do("HSET", "foo", "title", "hello")
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", reply)
+	p.moved(keySlot("baz"), "10.0.0.2:7000")
+
+	// ASK does not
+	reply, err = conn.Do("HSET", "baz", "title", "hello")
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", reply)
+	_, err = conn.Do("HSET", "foo", "price", 1)
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"CLUSTER SLOTS", "HSET foo title hello", "ASKING", "HSET baz title hello", "HSET foo price 1"}, a.commands)
+	assert.Equal(t, []string{"HSET foo title hello", "HSET baz title hello"}, b.commands)
+	assert.Equal(t, "10.0.0.2:7000", p.slots[keySlot("baz")])
+}
+
+func TestClusterPool_pipeline(t *testing.T) {
+	a := &scriptedConn{replies: []interface{}{"hash", "a1", "moved"}}
+	b := &scriptedConn{replies: []interface{}{"hash", redis.Error("MOVED " + strconv.Itoa(keySlot("foo")) + " 10.0.0.1:7000")}}
+	p := testClusterPool("foo", a, b)
+
+	conn := p.Get()
+	assert.Nil(t, conn.Send("SCRIPT", "LOAD", "return 1"))
+	assert.Nil(t, conn.Send("HSET", "bar", "title", "a1"))
+	assert.Nil(t, conn.Send("HSET", "foo", "title", "b1"))
+	assert.Nil(t, conn.Flush())
+	var replies []interface{}
+	for ii := 0; ii < 3; ii++ {
+		reply, err := conn.Receive()
+		assert.Nil(t, err)
+		replies = append(replies, reply)
+	}
+	assert.Equal(t, []interface{}{"hash", "a1", "moved"}, replies)
+	_, err := conn.Receive()
+	assert.NotNil(t, err)
+
+	assert.Equal(t, []string{"CLUSTER SLOTS", "SCRIPT LOAD return 1", "HSET bar title a1", "HSET foo title b1"}, a.commands)
+	assert.Equal(t, []string{"SCRIPT LOAD return 1", "HSET foo title b1"}, b.commands)
+	assert.Nil(t, conn.Close())
+	assert.NotNil(t, conn.Send("PING"))
+}
+
+func TestClusterPool_DoContext(t *testing.T) {
+	a := &contextConn{scriptedConn: &scriptedConn{replies: []interface{}{"OK"}}}
+	b := &contextConn{scriptedConn: &scriptedConn{replies: []interface{}{"b1"}}}
+	p := testClusterPool("foo", a.scriptedConn, b.scriptedConn)
+	p.newPool = func(addr string) ConnPool {
+		if addr == "10.0.0.2:7000" {
+			return &contextPool{conn: b}
+		}
+		return &contextPool{conn: a}
+	}
+
+	conn := p.Get()
+	reply, err := redis.DoContext(conn, context.Background(), "HSET", "bar", "title", "a1")
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", reply)
+	assert.Nil(t, conn.Send("HSET", "foo", "title", "b1"))
+	assert.Nil(t, conn.Flush())
+	reply, err = redis.ReceiveContext(conn, context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "b1", reply)
+	assert.Equal(t, 1, a.withContext)
+	assert.Equal(t, 1, b.withContext)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = redis.DoContext(conn, ctx, "HSET", "bar", "title", "a2")
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, []string{"CLUSTER SLOTS", "HSET bar title a1"}, a.commands)
+}
+
+func TestClusterPool_HashWriter(t *testing.T) {
+	load := strings.TrimSpace("SCRIPT LOAD " + hashWriteScriptSrc)
+	a := &scriptedConn{replies: []interface{}{"hash", "OK"}}
+	b := &scriptedConn{replies: []interface{}{"hash", "OK"}}
+	p := testClusterPool("product:2", a, b)
+
+	w := NewHashWriter(NewClientFromPool(p, "idx"), NewIndexDefinition().AddPrefix("product:"))
+	assert.Nil(t, w.Index(NewDocument("1", 1).Set("title", "a"), NewDocument("2", 1).Set("title", "b")))
+	evalsha := "EVALSHA " + hashWriteScript.Hash() + " 1 "
+	assert.Equal(t, []string{"CLUSTER SLOTS", load, evalsha + "product:1 add 0 title a __score 1"}, a.commands)
+	assert.Equal(t, []string{load, evalsha + "product:2 add 0 title b __score 1"}, b.commands)
+}
+
+func TestNewClusterPool_options(t *testing.T) {
+	p := NewClusterPool([]string{"10.0.0.1:7000"}, WithAuth("", "secret"), WithMaxActive(10), WithIdleTimeout(time.Minute))
+	// the seed nodes and the nodes discovered with CLUSTER SLOTS or redirects
+	for _, addr := range []string{"10.0.0.1:7000", "10.0.0.2:7000"} {
+		pool, ok := p.nodePool(addr).(*SingleHostPool)
+		assert.True(t, ok)
+		assert.Equal(t, 10, pool.MaxActive)
+		assert.Equal(t, time.Minute, pool.IdleTimeout)
+	}
+	assert.Nil(t, p.Close())
+}
+
+func TestClusterPool_Close(t *testing.T) {
+	a := &scriptedConn{replies: []interface{}{int64(1)}}
+	b := &scriptedConn{}
+	p := testClusterPool("foo", a, b)
+
+	conn := p.Get()
+	_, err := conn.Do("HSET", "bar", "title", "hello")
+	assert.Nil(t, err)
+	assert.Nil(t, p.Close())
+	assert.Equal(t, 0, len(p.pools))
+
+	// no pools are created again for the held connections or the new ones
+	_, err = conn.Do("HSET", "foo", "title", "hello")
+	assert.Equal(t, errClusterPoolClosed, err)
+	_, err = p.Get().Do("HSET", "foo", "title", "hello")
+	assert.Equal(t, errClusterPoolClosed, err)
+	assert.Equal(t, errClusterPoolClosed, p.Get().Err())
+	assert.NotNil(t, p.Refresh())
+	assert.Equal(t, 0, len(p.pools))
+	assert.Equal(t, 0, len(b.commands))
+}
@@ -0,0 +1,116 @@
+package redisearch
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// SearchConfig is the configuration of the RediSearch module, as returned by FT.CONFIG GET *.
+// Options not supported by the server are left empty
+type SearchConfig struct {
+	// Timeout is the maximum duration of queries in milliseconds, 0 for no limit
+	Timeout uint64 `redis:"TIMEOUT"`
+
+	// OnTimeout is the policy of queries over the timeout, RETURN or FAIL
+	OnTimeout string `redis:"ON_TIMEOUT"`
+
+	MinPrefix           uint64 `redis:"MINPREFIX"`
+	MaxExpansions       uint64 `redis:"MAXEXPANSIONS"`
+	MaxPrefixExpansions uint64 `redis:"MAXPREFIXEXPANSIONS"`
+	MaxDocTableSize     uint64 `redis:"MAXDOCTABLESIZE"`
+
+	// MaxSearchResults and MaxAggregateResults are 0 when unlimited
+	MaxSearchResults    uint64 `redis:"MAXSEARCHRESULTS"`
+	MaxAggregateResults uint64 `redis:"MAXAGGREGATERESULTS"`
+
+	MinPhoneticTermLen uint64 `redis:"MIN_PHONETIC_TERM_LEN"`
+	DefaultDialect     uint64 `redis:"DEFAULT_DIALECT"`
+	CursorMaxIdle      uint64 `redis:"CURSOR_MAX_IDLE"`
+	UnionIteratorsHeap uint64 `redis:"UNION_ITERATORS_HEAP"`
+	ExtLoad            string `redis:"EXTLOAD"`
+	FrisoIni           string `redis:"FRISOINI"`
+
+	GCPolicy             string `redis:"GC_POLICY"`
+	GCScanSize           uint64 `redis:"GCSCANSIZE"`
+	ForkGCRunInterval    uint64 `redis:"FORK_GC_RUN_INTERVAL"`
+	ForkGCRetryInterval  uint64 `redis:"FORK_GC_RETRY_INTERVAL"`
+	ForkGCCleanThreshold uint64 `redis:"FORK_GC_CLEAN_THRESHOLD"`
+}
+
+// ConfigGet returns the value of a configuration option of the module, or of all of them with *
+func (i *Client) ConfigGet(option string) (map[string]string, error) {
+	return i.ConfigGetContext(context.Background(), option)
+}
+
+// ConfigGetContext is the context-aware version of ConfigGet
+func (i *Client) ConfigGetContext(ctx context.Context, option string) (map[string]string, error) {
+	conn, err := i.getConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	res, err := redis.Values(doContext(ctx, conn, "FT.CONFIG", "GET", option))
+	if err != nil {
+		return nil, err
+	}
+	config := make(map[string]string, len(res))
+	for _, pair := range res {
+		kv, err := redis.Values(pair, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(kv) != 2 {
+			continue
+		}
+		name, err := redis.String(kv[0], nil)
+		if err != nil {
+			return nil, err
+		}
+		// options without a value are reported as nil
+		value, _ := redis.String(kv[1], nil)
+		config[name] = value
+	}
+	return config, nil
+}
+
+// ConfigSet sets a configuration option of the module
+func (i *Client) ConfigSet(option, value string) error {
+	return i.ConfigSetContext(context.Background(), option, value)
+}
+
+// ConfigSetContext is the context-aware version of ConfigSet
+func (i *Client) ConfigSetContext(ctx context.Context, option, value string) error {
+	conn, err := i.getConn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = redis.String(doContext(ctx, conn, "FT.CONFIG", "SET", option, value))
+	return err
+}
+
+// Config returns the configuration of the module
+func (i *Client) Config() (*SearchConfig, error) {
+	return i.ConfigContext(context.Background())
+}
+
+// ConfigContext is the context-aware version of Config
+func (i *Client) ConfigContext(ctx context.Context) (*SearchConfig, error) {
+	values, err := i.ConfigGetContext(ctx, "*")
+	if err != nil {
+		return nil, err
+	}
+	config := &SearchConfig{}
+	v := reflect.ValueOf(config).Elem()
+	for name, value := range values {
+		if value != "" {
+			// as replied by redis, so that numbers are parsed
+			setStructTarget(v, name, []byte(value))
+		}
+	}
+	return config, nil
+}
@@ -0,0 +1,46 @@
+package redisearch
+
+import (
+	"testing"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_ConfigGet(t *testing.T) {
+	conn := &scriptedConn{replies: []interface{}{
+		[]interface{}{
+			[]interface{}{[]byte("TIMEOUT"), []byte("500")},
+			[]interface{}{[]byte("EXTLOAD"), nil},
+		},
+		"OK",
+		redis.Error("Invalid option"),
+	}}
+	c := NewClientFromPool(&scriptedPool{conn: conn}, "idx")
+
+	config, err := c.ConfigGet("*")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"TIMEOUT": "500", "EXTLOAD": ""}, config)
+	assert.Nil(t, c.ConfigSet("TIMEOUT", "100"))
+	assert.NotNil(t, c.ConfigSet("UNKNOWN", "1"))
+	assert.Equal(t, []string{"FT.CONFIG GET *", "FT.CONFIG SET TIMEOUT 100", "FT.CONFIG SET UNKNOWN 1"}, conn.commands)
+}
+
+func TestClient_Config(t *testing.T) {
+	conn := &scriptedConn{replies: []interface{}{
+		[]interface{}{
+			[]interface{}{[]byte("TIMEOUT"), []byte("500")},
+			[]interface{}{[]byte("ON_TIMEOUT"), []byte("return")},
+			[]interface{}{[]byte("MINPREFIX"), []byte("2")},
+			[]interface{}{[]byte("MAXSEARCHRESULTS"), []byte("unlimited")},
+			[]interface{}{[]byte("DEFAULT_DIALECT"), []byte("2")},
+			[]interface{}{[]byte("EXTLOAD"), nil},
+			[]interface{}{[]byte("NOT_IN_STRUCT"), []byte("1")},
+		},
+	}}
+	c := NewClientFromPool(&scriptedPool{conn: conn}, "idx")
+
+	config, err := c.Config()
+	assert.Nil(t, err)
+	assert.Equal(t, &SearchConfig{Timeout: 500, OnTimeout: "return", MinPrefix: 2, DefaultDialect: 2}, config)
+}
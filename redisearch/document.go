@@ -18,6 +18,9 @@ type Document struct {
 	Score      float32
 	Payload    []byte
 	Properties map[string]interface{}
+
+	// Distance is the distance of the document from the vector of a KNN query, see Query.SetKNN
+	Distance float64
 }
 
 
@@ -70,6 +73,12 @@ func (d Document) Set(name string, value interface{}) Document {
 	return d
 }
 
+// SetGeo sets a geo property to the given longitude/latitude pair, formatted as expected by GEO fields
+func (d Document) SetGeo(name string, lon, lat float64) Document {
+	d.Properties[name] = strconv.FormatFloat(lon, 'f', -1, 64) + "," + strconv.FormatFloat(lat, 'f', -1, 64)
+	return d
+}
+
 // All punctuation marks and whitespaces (besides underscores) separate the document and queries into tokens.
 // e.g. any character of `,.<>{}[]"':;!@#$%^&*()-+=~` will break the text into terms.
 // So the text `foo-bar.baz...bag` will be tokenized into `[foo, bar, baz, bag]`
@@ -103,3 +103,23 @@ func TestDocument_SetPayload(t *testing.T) {
 		})
 	}
 }
+
+func TestDocument_SetGeo(t *testing.T) {
+	tests := []struct {
+		name string
+		lon  float64
+		lat  float64
+		want string
+	}{
+		{"san-francisco", -122.4194, 37.7749, "-122.4194,37.7749"},
+		{"integers", 10, 20, "10,20"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := redisearch.NewDocument("doc1", 1.0).SetGeo("location", tt.lon, tt.lat)
+			if got := d.Properties["location"]; got != tt.want {
+				t.Errorf("SetGeo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Default names of the hash fields holding the document score, payload and language,
+// used when the index definition does not set them
+const (
+	DefaultScoreField    = "__score"
+	DefaultPayloadField  = "__payload"
+	DefaultLanguageField = "__language"
+)
+
+// hashWriteScriptSrc is the source of the script writing a document hash with the FT.ADD semantics:
+// ARGV holds the mode (add, replace or partial), the number of conditions, the conditions as
+// field/operator/value/numeric quadruplets and finally the field/value pairs of the document
+const hashWriteScriptSrc = `
+local function holds(current, op, value, numeric)
+	if not current then
+		return false
+	end
+	if numeric == '1' then
+		current, value = tonumber(current), tonumber(value)
+		if not current then
+			return false
+		end
+	end
+	if op == '<' then
+		return current < value
+	elseif op == '<=' then
+		return current <= value
+	elseif op == '>' then
+		return current > value
+	elseif op == '>=' then
+		return current >= value
+	elseif op == '==' then
+		return current == value
+	end
+	return current ~= value
+end
+
+local key = KEYS[1]
+local mode = ARGV[1]
+local n = tonumber(ARGV[2])
+local pos = 3
+if redis.call('EXISTS', key) == 1 then
+	if mode == 'add' then
+		return redis.error_reply('Document already exists')
+	end
+	for i = 1, n do
+		if not holds(redis.call('HGET', key, ARGV[pos]), ARGV[pos + 1], ARGV[pos + 2], ARGV[pos + 3]) then
+			return redis.status_reply('NOADD')
+		end
+		pos = pos + 4
+	end
+	if mode == 'replace' then
+		redis.call('DEL', key)
+	end
+else
+	pos = pos + 4 * n
+end
+redis.call('HSET', key, unpack(ARGV, pos))
+return redis.status_reply('OK')
+`
+
+var hashWriteScript = redis.NewScript(1, hashWriteScriptSrc)
+
+// HashWriter writes documents as Redis hashes, which a RediSearch 2.x index following their keys
+// indexes as they are written (see IndexDefinition). It replaces FT.ADD, that was removed in RediSearch 2.x.
+//
+// The IndexingOptions keep the FT.ADD semantics:
+// by default a document is added only if its hash does not exist yet, Replace overwrites the whole hash,
+// Partial only updates the given fields, and ReplaceCondition applies the update only if the existing
+// hash matches the condition
+type HashWriter struct {
+	client     *Client
+	definition *IndexDefinition
+}
+
+// NewHashWriter creates a new writer of the documents of the index of c.
+// The definition, which can be nil, is the one the index was created with:
+// it sets the key prefix and the names of the score, payload and language fields
+func NewHashWriter(c *Client, d *IndexDefinition) *HashWriter {
+	if d == nil {
+		d = NewIndexDefinition()
+	}
+	return &HashWriter{client: c, definition: d}
+}
+
+// Key returns the key of the hash of a document. Unless the id already starts with one of the prefixes
+// of the index definition, it is prefixed with the first one
+func (w *HashWriter) Key(docId string) string {
+	return w.definition.key(docId)
+}
+
+// Index writes a list of documents with the default options
+func (w *HashWriter) Index(docs ...Document) error {
+	return w.IndexOptions(DefaultIndexingOptions, docs...)
+}
+
+// IndexContext is the context-aware version of Index
+func (w *HashWriter) IndexContext(ctx context.Context, docs ...Document) error {
+	return w.IndexOptionsContext(ctx, DefaultIndexingOptions, docs...)
+}
+
+// IndexOptions writes the documents with the given options, pipelining the writes on a single connection.
+// Errors of single documents are reported by position in a MultiError
+func (w *HashWriter) IndexOptions(opts IndexingOptions, docs ...Document) error {
+	return w.IndexOptionsContext(context.Background(), opts, docs...)
+}
+
+// IndexOptionsContext is the context-aware version of IndexOptions
+func (w *HashWriter) IndexOptionsContext(ctx context.Context, opts IndexingOptions, docs ...Document) error {
+	if opts.NoSave {
+		return errors.New("NoSave is not supported when writing documents as hashes")
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+	replace := opts.Replace || opts.Partial
+	var conditions redis.Args
+	if replace && opts.ReplaceCondition != "" {
+		var err error
+		if conditions, err = parseReplaceCondition(opts.ReplaceCondition); err != nil {
+			return err
+		}
+	}
+	// partial updates without conditions are plain HSETs, everything else goes through the script
+	useScript := !opts.Partial || len(conditions) > 0
+	mode := "add"
+	if opts.Partial {
+		mode = "partial"
+	} else if replace {
+		mode = "replace"
+	}
+
+	conn, err := w.client.getConn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if useScript {
+		// the script is loaded once, and sent by hash in the pipeline
+		if _, err := doContext(ctx, conn, "SCRIPT", "LOAD", hashWriteScriptSrc); err != nil {
+			return err
+		}
+	}
+
+	n := 0
+	var merr MultiError
+	for ii, doc := range docs {
+		key := w.Key(doc.Id)
+		fields := w.fields(doc, opts)
+		if useScript {
+			args := redis.Args{key, mode, len(conditions) / 4}
+			args = append(args, conditions...)
+			err = hashWriteScript.SendHash(conn, append(args, fields...)...)
+		} else {
+			err = conn.Send("HSET", append(redis.Args{key}, fields...)...)
+		}
+		if err != nil {
+			if merr == nil {
+				merr = NewMultiError(len(docs))
+			}
+			merr[ii] = err
+			return merr
+		}
+		n++
+	}
+
+	if err := conn.Flush(); err != nil {
+		return err
+	}
+
+	for ii := 0; ii < n; ii++ {
+		if _, err := receiveContext(ctx, conn); err != nil {
+			if err == ctx.Err() {
+				return err
+			}
+			if merr == nil {
+				merr = NewMultiError(len(docs))
+			}
+			merr[ii] = err
+		}
+	}
+
+	if merr == nil {
+		return nil
+	}
+	return merr
+}
+
+// fields returns the field/value pairs of the hash of a document, with the properties sorted by name
+func (w *HashWriter) fields(doc Document, opts IndexingOptions) redis.Args {
+	keys := make([]string, 0, len(doc.Properties))
+	for k := range doc.Properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	args := make(redis.Args, 0, 2*len(keys)+6)
+	for _, k := range keys {
+		args = append(args, k, doc.Properties[k])
+	}
+
+	scoreField, payloadField, languageField := DefaultScoreField, DefaultPayloadField, DefaultLanguageField
+	if w.definition.ScoreField != "" {
+		scoreField = w.definition.ScoreField
+	}
+	if w.definition.PayloadField != "" {
+		payloadField = w.definition.PayloadField
+	}
+	if w.definition.LanguageField != "" {
+		languageField = w.definition.LanguageField
+	}
+	args = append(args, scoreField, doc.Score)
+	if doc.Payload != nil {
+		args = append(args, payloadField, doc.Payload)
+	}
+	if opts.Language != "" {
+		args = append(args, languageField, opts.Language)
+	}
+	return args
+}
+
+var replaceConditionClause = regexp.MustCompile(`^@(\w+)\s*(<=|>=|==|!=|<|>)\s*(.+)$`)
+
+// parseReplaceCondition translates a replace condition to the field/operator/value/numeric quadruplets
+// evaluated by hashWriteScript. Only comparisons of a field with a number or a quoted string,
+// joined by &&, are supported
+func parseReplaceCondition(condition string) (redis.Args, error) {
+	args := redis.Args{}
+	for _, clause := range strings.Split(condition, "&&") {
+		clause = strings.TrimSpace(clause)
+		m := replaceConditionClause.FindStringSubmatch(clause)
+		if m == nil {
+			return nil, fmt.Errorf("Unsupported replace condition %q: expected @field <op> value clauses joined by &&", clause)
+		}
+		value := strings.TrimSpace(m[3])
+		numeric := "1"
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value, numeric = value[1:len(value)-1], "0"
+		} else if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return nil, fmt.Errorf("Unsupported replace condition %q: %s is neither a number nor a quoted string", clause, value)
+		}
+		args = append(args, m[1], m[2], value, numeric)
+	}
+	return args, nil
+}
@@ -0,0 +1,110 @@
+package redisearch
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashWriter_Key(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition *IndexDefinition
+		docId      string
+		want       string
+	}{
+		{"no-definition", nil, "doc1", "doc1"},
+		{"no-prefix", NewIndexDefinition(), "doc1", "doc1"},
+		{"prefixed", NewIndexDefinition().AddPrefix("product:"), "doc1", "product:doc1"},
+		{"already-prefixed", NewIndexDefinition().AddPrefix("product:").AddPrefix("item:"), "item:doc1", "item:doc1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewHashWriter(NewClientFromPool(&scriptedPool{}, "idx"), tt.definition)
+			if got := w.Key(tt.docId); got != tt.want {
+				t.Errorf("Key() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashWriter_IndexOptions(t *testing.T) {
+	load := strings.TrimSpace("SCRIPT LOAD " + hashWriteScriptSrc)
+	evalsha := "EVALSHA " + hashWriteScript.Hash() + " 1 "
+	definition := NewIndexDefinition().AddPrefix("product:").SetScoreField("rank")
+	doc := NewDocument("doc1", 0.5).Set("title", "hello").Set("price", 10)
+	tests := []struct {
+		name string
+		opts IndexingOptions
+		want []string
+	}{
+		{"add", DefaultIndexingOptions,
+			[]string{load, evalsha + "product:doc1 add 0 price 10 title hello rank 0.5"}},
+		{"replace", IndexingOptions{Replace: true, Language: "italian"},
+			[]string{load, evalsha + "product:doc1 replace 0 price 10 title hello rank 0.5 __language italian"}},
+		{"partial", IndexingOptions{Partial: true},
+			[]string{"HSET product:doc1 price 10 title hello rank 0.5"}},
+		{"replace-condition", IndexingOptions{Replace: true, ReplaceCondition: "@price < 20 && @title != 'world'"},
+			[]string{load, evalsha + "product:doc1 replace 2 price < 20 1 title != world 0 price 10 title hello rank 0.5"}},
+		{"partial-condition", IndexingOptions{Partial: true, ReplaceCondition: "@price>=5"},
+			[]string{load, evalsha + "product:doc1 partial 1 price >= 5 1 price 10 title hello rank 0.5"}},
+		{"add-ignores-condition", IndexingOptions{ReplaceCondition: "@price>=5"},
+			[]string{load, evalsha + "product:doc1 add 0 price 10 title hello rank 0.5"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &scriptedConn{replies: []interface{}{"hash", "OK"}}
+			w := NewHashWriter(NewClientFromPool(&scriptedPool{conn: conn}, "idx"), definition)
+			assert.Nil(t, w.IndexOptions(tt.opts, doc))
+			assert.Equal(t, tt.want, conn.commands)
+			assert.Equal(t, 1, conn.closed)
+		})
+	}
+}
+
+func TestHashWriter_IndexOptions_errors(t *testing.T) {
+	conn := &scriptedConn{replies: []interface{}{"hash", "OK", redis.Error("Document already exists"), "OK"}}
+	w := NewHashWriter(NewClientFromPool(&scriptedPool{conn: conn}, "idx"), nil)
+	err := w.Index(NewDocument("doc1", 1), NewDocument("doc2", 1), NewDocument("doc3", 1))
+	assert.Equal(t, MultiError{nil, redis.Error("Document already exists"), nil}, err)
+
+	conn = &scriptedConn{}
+	w = NewHashWriter(NewClientFromPool(&scriptedPool{conn: conn}, "idx"), nil)
+	assert.NotNil(t, w.IndexOptions(IndexingOptions{NoSave: true}, NewDocument("doc1", 1)))
+	assert.NotNil(t, w.IndexOptions(IndexingOptions{Replace: true, ReplaceCondition: "@price + 1 > 2"}, NewDocument("doc1", 1)))
+	assert.Nil(t, w.Index())
+	assert.Equal(t, 0, len(conn.commands))
+}
+
+func Test_parseReplaceCondition(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition string
+		want      redis.Args
+		wantErr   bool
+	}{
+		{"numeric", "@price < 20", redis.Args{"price", "<", "20", "1"}, false},
+		{"no-spaces", "@price>=-1.5", redis.Args{"price", ">=", "-1.5", "1"}, false},
+		{"quoted", `@title == "hello world"`, redis.Args{"title", "==", "hello world", "0"}, false},
+		{"single-quoted", `@title != 'hello'`, redis.Args{"title", "!=", "hello", "0"}, false},
+		{"and", "@price > 1 && @stock <= 3", redis.Args{"price", ">", "1", "1", "stock", "<=", "3", "1"}, false},
+		{"or", "@price > 1 || @stock <= 3", nil, true},
+		{"unquoted-string", "@title == hello", nil, true},
+		{"expression", "@price + 1 > 2", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseReplaceCondition(tt.condition)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseReplaceCondition() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseReplaceCondition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// scriptedConn is a redis.Conn replying to the commands with a fixed list of replies
+type scriptedConn struct {
+	redis.Conn
+	replies  []interface{}
+	commands []string
+	pending  []interface{}
+	err      error
+	closed   int
+}
+
+func (c *scriptedConn) Do(commandName string, args ...interface{}) (interface{}, error) {
+	command := []interface{}{commandName}
+	for _, arg := range args {
+		if b, ok := arg.([]byte); ok {
+			arg = string(b)
+		}
+		command = append(command, arg)
+	}
+	c.commands = append(c.commands, strings.TrimSpace(fmt.Sprintln(command...)))
+	if len(c.replies) == 0 {
+		return nil, errors.New("unexpected command")
+	}
+	reply := c.replies[0]
+	c.replies = c.replies[1:]
+	if err, ok := reply.(error); ok {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// Send runs the command right away, and queues its reply for Receive
+func (c *scriptedConn) Send(commandName string, args ...interface{}) error {
+	reply, err := c.Do(commandName, args...)
+	if err != nil {
+		reply = err
+	}
+	c.pending = append(c.pending, reply)
+	return nil
+}
+
+func (c *scriptedConn) Flush() error {
+	return nil
+}
+
+func (c *scriptedConn) Receive() (interface{}, error) {
+	reply := c.pending[0]
+	c.pending = c.pending[1:]
+	if err, ok := reply.(error); ok {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *scriptedConn) Err() error {
+	return c.err
+}
+
+func (c *scriptedConn) Close() error {
+	c.closed++
+	return nil
+}
+
+type scriptedPool struct {
+	conn *scriptedConn
+	gets int
+}
+
+func (p *scriptedPool) Get() redis.Conn {
+	p.gets++
+	return p.conn
+}
+
+// contextConn is a scriptedConn implementing redis.ConnWithContext, counting the calls of its context-aware methods
+type contextConn struct {
+	*scriptedConn
+	withContext int
+}
+
+func (c *contextConn) DoContext(ctx context.Context, commandName string, args ...interface{}) (interface{}, error) {
+	c.withContext++
+	return c.Do(commandName, args...)
+}
+
+func (c *contextConn) ReceiveContext(ctx context.Context) (interface{}, error) {
+	c.withContext++
+	return c.Receive()
+}
+
+type contextPool struct {
+	conn *contextConn
+}
+
+func (p *contextPool) Get() redis.Conn {
+	return p.conn
+}
+
+// commandConn is a redis.Conn replying to each command with the reply registered for it,
+// safe for concurrent use
+type commandConn struct {
+	redis.Conn
+	sync.Mutex
+	replies map[string]interface{}
+}
+
+func (c *commandConn) Do(commandName string, args ...interface{}) (interface{}, error) {
+	c.Lock()
+	defer c.Unlock()
+	command := strings.TrimSpace(fmt.Sprintln(append([]interface{}{commandName}, args...)...))
+	reply, ok := c.replies[command]
+	if !ok {
+		return nil, fmt.Errorf("unexpected command %s", command)
+	}
+	if err, ok := reply.(error); ok {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *commandConn) Close() error {
+	return nil
+}
+
+type commandPool struct {
+	conn *commandConn
+}
+
+func (p *commandPool) Get() redis.Conn {
+	return p.conn
+}
@@ -0,0 +1,142 @@
+package redisearch
+
+import (
+	"strings"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// IndexType is the type of the keys followed by an index
+type IndexType string
+
+const (
+	// HashIndex indexes the keys stored as Redis hashes (the default)
+	HashIndex IndexType = "HASH"
+
+	// JSONIndex indexes the keys stored as RedisJSON documents
+	JSONIndex IndexType = "JSON"
+)
+
+// IndexDefinition defines which keys are followed by an index in RediSearch 2.x, and how they are scored.
+// Keys matching the definition are indexed as soon as they are written, e.g. with HSET
+type IndexDefinition struct {
+	// IndexOn is the type of the indexed keys, hashes when empty
+	IndexOn IndexType
+
+	// Prefix restricts the index to the keys starting with any of the prefixes. All the keys are indexed when empty
+	Prefix []string
+
+	// FilterExpression is an aggregation expression, the keys are indexed only if it evaluates to true, i.e. @age>16
+	FilterExpression string
+
+	// Language is the default language of the documents
+	Language string
+
+	// LanguageField is the document field holding the language of the document
+	LanguageField string
+
+	// Score is the default score of the documents, 1.0 when zero
+	Score float64
+
+	// ScoreField is the document field holding the score of the document
+	ScoreField string
+
+	// PayloadField is the document field holding the binary payload of the document
+	PayloadField string
+}
+
+// NewIndexDefinition creates a new index definition following all the hash keys
+func NewIndexDefinition() *IndexDefinition {
+	return &IndexDefinition{IndexOn: HashIndex}
+}
+
+// SetIndexOn sets the type of the indexed keys
+func (d *IndexDefinition) SetIndexOn(indexOn IndexType) *IndexDefinition {
+	d.IndexOn = indexOn
+	return d
+}
+
+// AddPrefix adds a key prefix to follow
+func (d *IndexDefinition) AddPrefix(prefix string) *IndexDefinition {
+	d.Prefix = append(d.Prefix, prefix)
+	return d
+}
+
+// SetFilterExpression sets the expression filtering the indexed keys
+func (d *IndexDefinition) SetFilterExpression(filter string) *IndexDefinition {
+	d.FilterExpression = filter
+	return d
+}
+
+// SetLanguage sets the default language of the documents
+func (d *IndexDefinition) SetLanguage(language string) *IndexDefinition {
+	d.Language = language
+	return d
+}
+
+// SetLanguageField sets the field holding the language of the documents
+func (d *IndexDefinition) SetLanguageField(field string) *IndexDefinition {
+	d.LanguageField = field
+	return d
+}
+
+// SetScore sets the default score of the documents
+func (d *IndexDefinition) SetScore(score float64) *IndexDefinition {
+	d.Score = score
+	return d
+}
+
+// SetScoreField sets the field holding the score of the documents
+func (d *IndexDefinition) SetScoreField(field string) *IndexDefinition {
+	d.ScoreField = field
+	return d
+}
+
+// SetPayloadField sets the field holding the payload of the documents
+func (d *IndexDefinition) SetPayloadField(field string) *IndexDefinition {
+	d.PayloadField = field
+	return d
+}
+
+// Serialize appends the FT.CREATE arguments of the definition to args
+func (d *IndexDefinition) Serialize(args redis.Args) redis.Args {
+	if d.IndexOn != "" {
+		args = append(args, "ON", string(d.IndexOn))
+	}
+	if len(d.Prefix) > 0 {
+		args = args.Add("PREFIX", len(d.Prefix)).AddFlat(d.Prefix)
+	}
+	if d.FilterExpression != "" {
+		args = append(args, "FILTER", d.FilterExpression)
+	}
+	if d.Language != "" {
+		args = append(args, "LANGUAGE", d.Language)
+	}
+	if d.LanguageField != "" {
+		args = append(args, "LANGUAGE_FIELD", d.LanguageField)
+	}
+	if d.Score != 0 {
+		args = append(args, "SCORE", d.Score)
+	}
+	if d.ScoreField != "" {
+		args = append(args, "SCORE_FIELD", d.ScoreField)
+	}
+	if d.PayloadField != "" {
+		args = append(args, "PAYLOAD_FIELD", d.PayloadField)
+	}
+	return args
+}
+
+// key returns the key of a document id, prefixed with the first prefix of the definition
+// unless it already starts with one of them
+func (d *IndexDefinition) key(docId string) string {
+	if len(d.Prefix) == 0 {
+		return docId
+	}
+	for _, prefix := range d.Prefix {
+		if strings.HasPrefix(docId, prefix) {
+			return docId
+		}
+	}
+	return d.Prefix[0] + docId
+}
@@ -0,0 +1,93 @@
+package redisearch
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// DefaultInfoConcurrency is the number of concurrent FT.INFO calls of InfoAll when not set
+const DefaultInfoConcurrency = 8
+
+// ListIndexes returns the names of all the indexes, sorted, with FT._LIST
+func ListIndexes(pool ConnPool) ([]string, error) {
+	return ListIndexesContext(context.Background(), pool)
+}
+
+// ListIndexesContext is the context-aware version of ListIndexes
+func ListIndexesContext(ctx context.Context, pool ConnPool) ([]string, error) {
+	conn, err := getPoolConn(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	names, err := redis.Strings(doContext(ctx, conn, "FT._LIST"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// InfoAll returns the IndexInfo of all the indexes, in the order of ListIndexes, running at most
+// concurrency FT.INFO calls at a time (DefaultInfoConcurrency when not positive).
+// Indexes dropped in the meantime are skipped. On errors, the other calls are canceled and the first error is returned
+func InfoAll(pool ConnPool, concurrency int) ([]*IndexInfo, error) {
+	return InfoAllContext(context.Background(), pool, concurrency)
+}
+
+// InfoAllContext is the context-aware version of InfoAll
+func InfoAllContext(ctx context.Context, pool ConnPool, concurrency int) ([]*IndexInfo, error) {
+	names, err := ListIndexesContext(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultInfoConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	infos := make([]*IndexInfo, len(names))
+	errs := make([]error, len(names))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for ii, name := range names {
+		wg.Add(1)
+		go func(ii int, name string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[ii] = ctx.Err()
+				return
+			}
+			infos[ii], errs[ii] = NewClientFromPool(pool, name).InfoContext(ctx)
+			if errs[ii] != nil && !isUnknownIndexError(errs[ii]) {
+				cancel()
+			}
+		}(ii, name)
+	}
+	wg.Wait()
+
+	ret := make([]*IndexInfo, 0, len(names))
+	var firstErr error
+	for ii, err := range errs {
+		switch {
+		case err == nil:
+			ret = append(ret, infos[ii])
+		case isUnknownIndexError(err):
+		case firstErr == nil || firstErr == context.Canceled:
+			// prefer the error that caused the cancellation
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return ret, nil
+}
@@ -0,0 +1,43 @@
+package redisearch
+
+import (
+	"testing"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInfoAll(t *testing.T) {
+	info := func(name string, docs int64) []interface{} {
+		return []interface{}{"index_name", []byte(name), "num_docs", docs}
+	}
+	conn := &commandConn{replies: map[string]interface{}{
+		"FT._LIST":         []interface{}{[]byte("products"), []byte("dropped"), []byte("articles")},
+		"FT.INFO products": info("products", 3),
+		"FT.INFO articles": info("articles", 5),
+		"FT.INFO dropped":  redis.Error("Unknown Index name"),
+	}}
+	pool := &commandPool{conn: conn}
+
+	names, err := ListIndexes(pool)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"articles", "dropped", "products"}, names)
+
+	for _, concurrency := range []int{0, 1, 3} {
+		infos, err := InfoAll(pool, concurrency)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(infos))
+		assert.Equal(t, "articles", infos[0].Name)
+		assert.Equal(t, uint64(5), infos[0].DocCount)
+		assert.Equal(t, "products", infos[1].Name)
+		assert.Equal(t, uint64(3), infos[1].DocCount)
+	}
+
+	conn.replies["FT.INFO products"] = redis.Error("ERR broken")
+	_, err = InfoAll(pool, 1)
+	assert.Equal(t, redis.Error("ERR broken"), err)
+
+	conn.replies["FT._LIST"] = redis.Error("ERR unknown command")
+	_, err = InfoAll(pool, 1)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,152 @@
+package redisearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// JSONRootField is the property holding the whole JSON document in the results of indexes created ON JSON
+const JSONRootField = "$"
+
+// JSONDocument is a document stored with RedisJSON, for indexes created ON JSON
+type JSONDocument struct {
+	Id string
+	// Value is marshalled with encoding/json, a json.RawMessage is stored as it is
+	Value interface{}
+}
+
+// NewJSONDocument creates a JSON document with the specific id and value
+func NewJSONDocument(id string, value interface{}) JSONDocument {
+	return JSONDocument{Id: id, Value: value}
+}
+
+// JSON returns the JSON of a document returned by a search on an index created ON JSON,
+// and whether the document has it
+func (d *Document) JSON() (json.RawMessage, bool) {
+	switch v := d.Properties[JSONRootField].(type) {
+	case string:
+		return json.RawMessage(v), true
+	case []byte:
+		return json.RawMessage(v), true
+	}
+	return nil, false
+}
+
+// DecodeJSON unmarshals the JSON of a document returned by a search on an index created ON JSON into v
+func (d *Document) DecodeJSON(v interface{}) error {
+	raw, ok := d.JSON()
+	if !ok {
+		return errors.New("DecodeJSON: the document has no JSON value, is the index created ON JSON?")
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// JSONWriter writes documents with JSON.SET, to be indexed by an index created ON JSON following their keys
+// (see IndexDefinition). Only the Replace indexing option is supported:
+// by default a document is added only if its key does not exist yet, while Replace overwrites it
+type JSONWriter struct {
+	client     *Client
+	definition *IndexDefinition
+}
+
+// NewJSONWriter creates a new writer of the documents of the index of c.
+// The definition, which can be nil, is the one the index was created with, and sets the key prefix
+func NewJSONWriter(c *Client, d *IndexDefinition) *JSONWriter {
+	if d == nil {
+		d = NewIndexDefinition().SetIndexOn(JSONIndex)
+	}
+	return &JSONWriter{client: c, definition: d}
+}
+
+// Key returns the key of a document. Unless the id already starts with one of the prefixes
+// of the index definition, it is prefixed with the first one
+func (w *JSONWriter) Key(docId string) string {
+	return w.definition.key(docId)
+}
+
+// Index writes a list of documents with the default options
+func (w *JSONWriter) Index(docs ...JSONDocument) error {
+	return w.IndexOptions(DefaultIndexingOptions, docs...)
+}
+
+// IndexContext is the context-aware version of Index
+func (w *JSONWriter) IndexContext(ctx context.Context, docs ...JSONDocument) error {
+	return w.IndexOptionsContext(ctx, DefaultIndexingOptions, docs...)
+}
+
+// IndexOptions writes the documents with the given options, pipelining the writes on a single connection.
+// Errors of single documents are reported by position in a MultiError
+func (w *JSONWriter) IndexOptions(opts IndexingOptions, docs ...JSONDocument) error {
+	return w.IndexOptionsContext(context.Background(), opts, docs...)
+}
+
+// IndexOptionsContext is the context-aware version of IndexOptions
+func (w *JSONWriter) IndexOptionsContext(ctx context.Context, opts IndexingOptions, docs ...JSONDocument) error {
+	if opts.NoSave || opts.Partial || opts.ReplaceCondition != "" || opts.Language != "" {
+		return errors.New("Only the Replace option is supported when writing JSON documents")
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+
+	conn, err := w.client.getConn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// positions of the documents sent in the pipeline, the ones that cannot be marshalled are skipped
+	sent := make([]int, 0, len(docs))
+	var merr MultiError
+	for ii, doc := range docs {
+		value, err := json.Marshal(doc.Value)
+		if err != nil {
+			if merr == nil {
+				merr = NewMultiError(len(docs))
+			}
+			merr[ii] = err
+			continue
+		}
+		args := redis.Args{w.Key(doc.Id), JSONRootField, value}
+		if !opts.Replace {
+			args = append(args, "NX")
+		}
+		if err := conn.Send("JSON.SET", args...); err != nil {
+			if merr == nil {
+				merr = NewMultiError(len(docs))
+			}
+			merr[ii] = err
+			return merr
+		}
+		sent = append(sent, ii)
+	}
+
+	if err := conn.Flush(); err != nil {
+		return err
+	}
+
+	for _, ii := range sent {
+		reply, err := receiveContext(ctx, conn)
+		if err != nil && err == ctx.Err() {
+			return err
+		}
+		if err == nil && reply == nil {
+			// NX was not met
+			err = errors.New("Document already exists")
+		}
+		if err != nil {
+			if merr == nil {
+				merr = NewMultiError(len(docs))
+			}
+			merr[ii] = err
+		}
+	}
+
+	if merr == nil {
+		return nil
+	}
+	return merr
+}
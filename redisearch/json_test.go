@@ -0,0 +1,76 @@
+package redisearch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+type testProduct struct {
+	Title string   `json:"title"`
+	Tags  []string `json:"tags"`
+	Price float64  `json:"price"`
+}
+
+func TestDocument_DecodeJSON(t *testing.T) {
+	doc := NewDocument("product:1", 1).Set(JSONRootField, `{"title":"hello","tags":["a","b"],"price":10.5}`)
+	raw, ok := doc.JSON()
+	assert.True(t, ok)
+	assert.Equal(t, json.RawMessage(`{"title":"hello","tags":["a","b"],"price":10.5}`), raw)
+
+	var p testProduct
+	assert.Nil(t, doc.DecodeJSON(&p))
+	assert.Equal(t, testProduct{"hello", []string{"a", "b"}, 10.5}, p)
+
+	doc = NewDocument("product:1", 1).Set("title", "hello")
+	_, ok = doc.JSON()
+	assert.False(t, ok)
+	assert.NotNil(t, doc.DecodeJSON(&p))
+}
+
+func TestJSONWriter_IndexOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts IndexingOptions
+		want []string
+	}{
+		{"add", DefaultIndexingOptions, []string{
+			`JSON.SET product:1 $ {"title":"hello","tags":["a"],"price":10} NX`,
+			`JSON.SET product:2 $ {"title":"world"} NX`,
+		}},
+		{"replace", IndexingOptions{Replace: true}, []string{
+			`JSON.SET product:1 $ {"title":"hello","tags":["a"],"price":10}`,
+			`JSON.SET product:2 $ {"title":"world"}`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &scriptedConn{replies: []interface{}{"OK", "OK"}}
+			w := NewJSONWriter(NewClientFromPool(&scriptedPool{conn: conn}, "idx"),
+				NewIndexDefinition().SetIndexOn(JSONIndex).AddPrefix("product:"))
+			err := w.IndexOptions(tt.opts,
+				NewJSONDocument("1", testProduct{"hello", []string{"a"}, 10}),
+				NewJSONDocument("product:2", json.RawMessage(`{"title":"world"}`)))
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, conn.commands)
+		})
+	}
+}
+
+func TestJSONWriter_IndexOptions_errors(t *testing.T) {
+	// an existing document, a document that cannot be marshalled and a failed write
+	conn := &scriptedConn{replies: []interface{}{nil, redis.Error("ERR wrong type")}}
+	w := NewJSONWriter(NewClientFromPool(&scriptedPool{conn: conn}, "idx"), nil)
+	err := w.Index(NewJSONDocument("1", "hello"), NewJSONDocument("2", make(chan int)), NewJSONDocument("3", 1))
+	merr, ok := err.(MultiError)
+	assert.True(t, ok)
+	assert.Equal(t, 3, len(merr))
+	assert.Equal(t, "Document already exists", merr[0].Error())
+	assert.NotNil(t, merr[1])
+	assert.Equal(t, redis.Error("ERR wrong type"), merr[2])
+	assert.Equal(t, 2, len(conn.commands))
+
+	assert.NotNil(t, w.IndexOptions(IndexingOptions{Partial: true}, NewJSONDocument("1", "hello")))
+}
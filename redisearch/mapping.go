@@ -0,0 +1,485 @@
+package redisearch
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// structTag is the struct tag used to map struct fields to index fields, e.g.
+//
+//	type Movie struct {
+//		Title    string    `redisearch:"title,sortable,weight=5"`
+//		Year     int       `redisearch:"year,sortable"`
+//		Genres   []string  `redisearch:"genres,separator=;"`
+//		Released time.Time `redisearch:"released,omitempty"`
+//		Internal string    `redisearch:"-"`
+//	}
+//
+// The first element is the field name, defaulting to the struct field name. The field type is
+// derived from the Go type: strings are TEXT, numbers and time.Time (as unix seconds) are NUMERIC,
+// bools and string slices are TAG. It can be forced with one of the text, numeric, tag or geo options.
+// The other options are sortable, noindex, nostem, weight=<float>, separator=<char> and omitempty
+const structTag = "redisearch"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// structField is a struct field mapped to an index field
+type structField struct {
+	index     []int
+	name      string
+	fieldType FieldType
+	sortable  bool
+	noIndex   bool
+	noStem    bool
+	omitEmpty bool
+	weight    float32
+	separator byte
+}
+
+// field returns the schema field of the struct field
+func (sf structField) field() Field {
+	switch sf.fieldType {
+	case NumericField:
+		return NewNumericFieldOptions(sf.name, NumericFieldOptions{Sortable: sf.sortable, NoIndex: sf.noIndex})
+	case TagField:
+		return NewTagFieldOptions(sf.name, TagFieldOptions{Separator: sf.separator, Sortable: sf.sortable, NoIndex: sf.noIndex})
+	case GeoField:
+		return NewGeoFieldOptions(sf.name, GeoFieldOptions{NoIndex: sf.noIndex})
+	default:
+		return NewTextFieldOptions(sf.name, TextFieldOptions{Weight: sf.weight, Sortable: sf.sortable, NoStem: sf.noStem, NoIndex: sf.noIndex})
+	}
+}
+
+var structFieldsCache sync.Map // map[reflect.Type][]structField
+
+// cachedStructFields returns the mapped fields of a struct type
+func cachedStructFields(t reflect.Type) ([]structField, error) {
+	if f, ok := structFieldsCache.Load(t); ok {
+		return f.([]structField), nil
+	}
+	fields, err := parseStructFields(t, nil)
+	if err != nil {
+		return nil, err
+	}
+	structFieldsCache.Store(t, fields)
+	return fields, nil
+}
+
+func parseStructFields(t reflect.Type, index []int) ([]structField, error) {
+	fields := make([]structField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, tagged := f.Tag.Lookup(structTag)
+		if tag == "-" {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+
+		// Flatten embedded structs without an explicit tag, like encoding/json does
+		if f.Anonymous && !tagged {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && ft != timeType {
+				embedded, err := parseStructFields(ft, fieldIndex)
+				if err != nil {
+					return nil, err
+				}
+				fields = append(fields, embedded...)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			// unexported field
+			continue
+		}
+
+		sf, err := parseStructField(f, tag)
+		if err != nil {
+			return nil, err
+		}
+		sf.index = fieldIndex
+		fields = append(fields, sf)
+	}
+	return fields, nil
+}
+
+func parseStructField(f reflect.StructField, tag string) (sf structField, err error) {
+	opts := strings.Split(tag, ",")
+	sf.name = opts[0]
+	if sf.name == "" {
+		sf.name = f.Name
+	}
+	if sf.fieldType, err = defaultFieldType(f.Type); err != nil {
+		return sf, fmt.Errorf("field %s: %s", f.Name, err)
+	}
+	for _, opt := range opts[1:] {
+		key, value := opt, ""
+		if pos := strings.IndexByte(opt, '='); pos != -1 {
+			key, value = opt[:pos], opt[pos+1:]
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "":
+		case "text":
+			sf.fieldType = TextField
+		case "numeric":
+			sf.fieldType = NumericField
+		case "tag":
+			sf.fieldType = TagField
+		case "geo":
+			sf.fieldType = GeoField
+		case "sortable":
+			sf.sortable = true
+		case "noindex":
+			sf.noIndex = true
+		case "nostem":
+			sf.noStem = true
+		case "omitempty":
+			sf.omitEmpty = true
+		case "weight":
+			weight, err := strconv.ParseFloat(value, 32)
+			if err != nil {
+				return sf, fmt.Errorf("field %s: invalid weight %q", f.Name, value)
+			}
+			sf.weight = float32(weight)
+		case "separator":
+			if len(value) != 1 {
+				return sf, fmt.Errorf("field %s: separator must be a single character, got %q", f.Name, value)
+			}
+			sf.separator = value[0]
+		default:
+			return sf, fmt.Errorf("field %s: unknown option %q", f.Name, opt)
+		}
+	}
+	if sf.fieldType == TagField && sf.separator == 0 {
+		sf.separator = ','
+	}
+	return sf, nil
+}
+
+// defaultFieldType returns the index field type of a Go type
+func defaultFieldType(t reflect.Type) (FieldType, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return NumericField, nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return TextField, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return NumericField, nil
+	case reflect.Bool:
+		return TagField, nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String {
+			return TagField, nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			return TextField, nil
+		}
+	case reflect.Array:
+		if t.Len() == 2 && t.Elem().Kind() == reflect.Float64 {
+			return GeoField, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported type %s", t)
+}
+
+// structValue returns the struct value pointed by v
+func structValue(v interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return rv, errors.New("nil struct pointer")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return rv, fmt.Errorf("expected a struct, got %T", v)
+	}
+	return rv, nil
+}
+
+// NewSchemaFromStruct creates a new Schema from the redisearch tags of a struct.
+// v can be a struct value or a pointer to a struct
+func NewSchemaFromStruct(v interface{}, opts Options) (*Schema, error) {
+	rv, err := structValue(v)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := cachedStructFields(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+	sc := NewSchema(opts)
+	for _, sf := range fields {
+		sc.AddField(sf.field())
+	}
+	return sc, nil
+}
+
+// NewDocumentFromStruct creates a document with the specific id and score,
+// whose properties are the redisearch tagged fields of a struct
+func NewDocumentFromStruct(id string, score float32, v interface{}) (Document, error) {
+	doc := NewDocument(id, score)
+	rv, err := structValue(v)
+	if err != nil {
+		return doc, err
+	}
+	fields, err := cachedStructFields(rv.Type())
+	if err != nil {
+		return doc, err
+	}
+	for _, sf := range fields {
+		fv, ok := fieldByIndex(rv, sf.index, false)
+		if !ok || (sf.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		value, err := encodeValue(fv, sf)
+		if err != nil {
+			return doc, fmt.Errorf("field %s: %s", sf.name, err)
+		}
+		doc.Set(sf.name, value)
+	}
+	return doc, nil
+}
+
+// Decode stores the document properties in the redisearch tagged fields of the struct pointed by v.
+// Properties returned by Search, Get and MultiGet are strings, and are converted to the type of the fields
+func (d *Document) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("Decode: expected a non-nil struct pointer, got %T", v)
+	}
+	rv, err := structValue(v)
+	if err != nil {
+		return fmt.Errorf("Decode: %s", err)
+	}
+	fields, err := cachedStructFields(rv.Type())
+	if err != nil {
+		return fmt.Errorf("Decode: %s", err)
+	}
+	for _, sf := range fields {
+		prop, found := d.Properties[sf.name]
+		if !found || prop == nil {
+			continue
+		}
+		fv, ok := fieldByIndex(rv, sf.index, true)
+		if !ok {
+			continue
+		}
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+			fv = fv.Elem()
+		}
+		if err := decodeValue(prop, fv, sf); err != nil {
+			return fmt.Errorf("Decode: field %s: %s", sf.name, err)
+		}
+	}
+	return nil
+}
+
+// fieldByIndex returns the nested field of v, allocating nil embedded struct pointers if alloc is set.
+// The fields behind a nil pointer to an unexported struct type are not found, as it cannot be allocated
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return v, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return v.Interface().(time.Time).IsZero()
+		}
+	}
+	return false
+}
+
+// encodeValue converts a struct field value to a document property value
+func encodeValue(v reflect.Value, sf structField) (interface{}, error) {
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).Unix(), nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Bytes(), nil
+		}
+		if v.Type().Elem().Kind() == reflect.String {
+			values := make([]string, v.Len())
+			for i := range values {
+				values[i] = v.Index(i).String()
+			}
+			return strings.Join(values, string(sf.separator)), nil
+		}
+	case reflect.Array:
+		if v.Len() == 2 && v.Type().Elem().Kind() == reflect.Float64 {
+			return strconv.FormatFloat(v.Index(0).Float(), 'f', -1, 64) + "," + strconv.FormatFloat(v.Index(1).Float(), 'f', -1, 64), nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported type %s", v.Type())
+}
+
+// decodeValue converts a document property value to the type of a struct field value
+func decodeValue(prop interface{}, v reflect.Value, sf structField) error {
+	var s string
+	switch p := prop.(type) {
+	case string:
+		s = p
+	case []byte:
+		s = string(p)
+	case []interface{}:
+		// array values, like the output of the TOLIST reducer in aggregations
+		if v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("cannot decode an array value into %s", v.Type())
+		}
+		values := reflect.MakeSlice(v.Type(), len(p), len(p))
+		for i, elem := range p {
+			if elem != nil {
+				values.Index(i).SetString(fmt.Sprint(elem))
+			}
+		}
+		v.Set(values)
+		return nil
+	default:
+		s = fmt.Sprint(p)
+	}
+
+	if v.Type() == timeType {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		sec := int64(f)
+		v.Set(reflect.ValueOf(time.Unix(sec, int64((f-float64(sec))*float64(time.Second)))))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			// numeric fields may be returned in a floating point notation
+			f, ferr := strconv.ParseFloat(s, 64)
+			if ferr != nil {
+				return err
+			}
+			n = int64(f)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			// as for the int kinds, unless negative
+			f, ferr := strconv.ParseFloat(s, 64)
+			if ferr != nil || f < 0 {
+				return err
+			}
+			n = uint64(f)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes([]byte(s))
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.String {
+			var values []string
+			if s != "" {
+				values = strings.Split(s, string(sf.separator))
+				for i := range values {
+					values[i] = strings.TrimSpace(values[i])
+				}
+			}
+			v.Set(reflect.ValueOf(values).Convert(v.Type()))
+			return nil
+		}
+		return fmt.Errorf("unsupported type %s", v.Type())
+	case reflect.Array:
+		if v.Len() == 2 && v.Type().Elem().Kind() == reflect.Float64 {
+			coords := strings.Split(s, ",")
+			if len(coords) != 2 {
+				return fmt.Errorf("invalid geo value %q", s)
+			}
+			for i, c := range coords {
+				f, err := strconv.ParseFloat(strings.TrimSpace(c), 64)
+				if err != nil {
+					return err
+				}
+				v.Index(i).SetFloat(f)
+			}
+			return nil
+		}
+		return fmt.Errorf("unsupported type %s", v.Type())
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
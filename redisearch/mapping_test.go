@@ -0,0 +1,205 @@
+package redisearch
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+type testMappingBase struct {
+	Brand string `redisearch:"brand,tag"`
+}
+
+type testMappingProduct struct {
+	testMappingBase
+	Title    string     `redisearch:"title,sortable,weight=5"`
+	Body     string     `redisearch:",nostem"`
+	Price    float64    `redisearch:"price,sortable"`
+	Stock    int        `redisearch:"stock"`
+	InStock  bool       `redisearch:"in_stock"`
+	Tags     []string   `redisearch:"tags,separator=;"`
+	Location [2]float64 `redisearch:"location"`
+	Released time.Time  `redisearch:"released,omitempty"`
+	Rating   *float32   `redisearch:"rating"`
+	Internal string     `redisearch:"-"`
+	private  string
+}
+
+func TestNewSchemaFromStruct(t *testing.T) {
+	sc, err := NewSchemaFromStruct(&testMappingProduct{}, DefaultOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := SerializeSchema(sc, redis.Args{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := redis.Args{"SCHEMA",
+		"brand", "TAG", "SEPARATOR", ",",
+		"title", "TEXT", "WEIGHT", float32(5), "SORTABLE",
+		"Body", "TEXT", "NOSTEM",
+		"price", "NUMERIC", "SORTABLE",
+		"stock", "NUMERIC",
+		"in_stock", "TAG", "SEPARATOR", ",",
+		"tags", "TAG", "SEPARATOR", ";",
+		"location", "GEO",
+		"released", "NUMERIC",
+		"rating", "NUMERIC",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewSchemaFromStruct() = %v, want %v", got, want)
+	}
+}
+
+func TestNewSchemaFromStruct_errors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"not-a-struct", 1},
+		{"nil-pointer", (*testMappingProduct)(nil)},
+		{"unsupported-type", struct {
+			M map[string]string
+		}{}},
+		{"unknown-option", struct {
+			S string `redisearch:"s,unknown"`
+		}{}},
+		{"invalid-weight", struct {
+			S string `redisearch:"s,weight=heavy"`
+		}{}},
+		{"invalid-separator", struct {
+			S []string `redisearch:"s,separator=;;"`
+		}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSchemaFromStruct(tt.v, DefaultOptions); err == nil {
+				t.Errorf("NewSchemaFromStruct() expected an error")
+			}
+		})
+	}
+}
+
+func TestNewDocumentFromStruct(t *testing.T) {
+	p := testMappingProduct{
+		testMappingBase: testMappingBase{Brand: "acme"},
+		Title:           "Hello world",
+		Body:            "foo bar",
+		Price:           10.5,
+		Stock:           3,
+		InStock:         true,
+		Tags:            []string{"a", "b c"},
+		Location:        [2]float64{-122.41, 37.77},
+		Internal:        "secret",
+	}
+	doc, err := NewDocumentFromStruct("doc1", 1, &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"brand":    "acme",
+		"title":    "Hello world",
+		"Body":     "foo bar",
+		"price":    "10.5",
+		"stock":    int64(3),
+		"in_stock": "true",
+		"tags":     "a;b c",
+		"location": "-122.41,37.77",
+	}
+	if !reflect.DeepEqual(doc.Properties, want) {
+		t.Errorf("NewDocumentFromStruct() = %v, want %v", doc.Properties, want)
+	}
+	if doc.Id != "doc1" || doc.Score != 1 {
+		t.Errorf("NewDocumentFromStruct() id/score = %v/%v", doc.Id, doc.Score)
+	}
+}
+
+func TestDocument_Decode(t *testing.T) {
+	doc := NewDocument("doc1", 1).
+		Set("brand", "acme").
+		Set("title", "Hello world").
+		Set("price", "10.5").
+		Set("stock", "3").
+		Set("in_stock", "true").
+		Set("tags", "a;b c").
+		Set("location", "-122.41,37.77").
+		Set("released", "1500000000").
+		Set("rating", "4.5").
+		Set("unknown", "ignored")
+
+	var got testMappingProduct
+	if err := doc.Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	rating := float32(4.5)
+	want := testMappingProduct{
+		testMappingBase: testMappingBase{Brand: "acme"},
+		Title:           "Hello world",
+		Price:           10.5,
+		Stock:           3,
+		InStock:         true,
+		Tags:            []string{"a", "b c"},
+		Location:        [2]float64{-122.41, 37.77},
+		Released:        time.Unix(1500000000, 0),
+		Rating:          &rating,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode() = %+v, want %+v", got, want)
+	}
+
+	if err := doc.Decode(got); err == nil {
+		t.Errorf("Decode() expected an error for a non pointer value")
+	}
+	bad := NewDocument("doc2", 1).Set("stock", "many")
+	if err := bad.Decode(&got); err == nil {
+		t.Errorf("Decode() expected an error for an invalid numeric value")
+	}
+}
+
+type testMappingHidden struct {
+	Color string `redisearch:"color"`
+}
+
+type testMappingCounts struct {
+	*testMappingHidden
+	Count uint  `redisearch:"count"`
+	Stock uint8 `redisearch:"stock"`
+}
+
+func TestDocument_Decode_uint(t *testing.T) {
+	doc := NewDocument("doc1", 1).Set("count", "3.0").Set("stock", "7")
+	var got testMappingCounts
+	if err := doc.Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Count != 3 || got.Stock != 7 {
+		t.Errorf("Decode() = %+v", got)
+	}
+	for _, value := range []string{"-1", "-1.5", "many"} {
+		bad := NewDocument("doc2", 1).Set("count", value)
+		if err := bad.Decode(&got); err == nil {
+			t.Errorf("Decode() expected an error for %s", value)
+		}
+	}
+}
+
+func TestDocument_Decode_unexportedEmbedded(t *testing.T) {
+	doc := NewDocument("doc1", 1).Set("color", "red").Set("count", "2")
+	// the nil pointer to the unexported struct cannot be allocated, its fields are skipped
+	var got testMappingCounts
+	if err := doc.Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.testMappingHidden != nil || got.Count != 2 {
+		t.Errorf("Decode() = %+v", got)
+	}
+	got = testMappingCounts{testMappingHidden: &testMappingHidden{}}
+	if err := doc.Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Color != "red" || got.Count != 2 {
+		t.Errorf("Decode() = %+v, %+v", got, got.testMappingHidden)
+	}
+}
@@ -0,0 +1,206 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// DocumentSource returns the documents a new index is backfilled with, one batch per call,
+// until it returns an empty batch
+type DocumentSource func(ctx context.Context) ([]Document, error)
+
+// Migration rebuilds the index behind an alias with a new schema, see Client.Migrate
+type Migration struct {
+	// Schema is the schema of the new index. Indexes with an IndexDefinition are backfilled
+	// by RediSearch with the existing keys matching the definition
+	Schema *Schema
+
+	// Source, when set, backfills the new index with its documents. They are written as hashes with
+	// HashWriter if the schema has an IndexDefinition, with FT.ADD otherwise
+	Source DocumentSource
+
+	// Version is the version of the new index, named <alias>_v<version>.
+	// When zero, it follows the version of the index currently behind the alias
+	Version int
+
+	// DropOld drops the previous index once the alias points to the new one, keeping its documents
+	DropOld bool
+
+	// PollInterval is the interval between the FT.INFO calls checking whether the backfill is over, 100ms by default
+	PollInterval time.Duration
+}
+
+// NewMigration creates a new migration to an index with the given schema
+func NewMigration(s *Schema) *Migration {
+	return &Migration{Schema: s, PollInterval: 100 * time.Millisecond}
+}
+
+// SetSource sets the source of the documents the new index is backfilled with
+func (m *Migration) SetSource(source DocumentSource) *Migration {
+	m.Source = source
+	return m
+}
+
+// SetVersion sets the version of the new index
+func (m *Migration) SetVersion(version int) *Migration {
+	m.Version = version
+	return m
+}
+
+// SetDropOld sets whether the previous index is dropped once the alias is switched
+func (m *Migration) SetDropOld(dropOld bool) *Migration {
+	m.DropOld = dropOld
+	return m
+}
+
+// SetPollInterval sets the interval between the checks of the backfill progress
+func (m *Migration) SetPollInterval(interval time.Duration) *Migration {
+	m.PollInterval = interval
+	return m
+}
+
+// Migrate rebuilds the index behind the alias the client is created with, without downtime:
+// the new versioned index, i.e. products_v7, is created and backfilled, and once it is fully indexed
+// the alias is switched to it with FT.ALIASUPDATE, or added with FT.ALIASADD if it does not exist yet.
+// If any step fails before the switch the new index is dropped, so that the alias keeps pointing
+// to the previous one. The documents written by the Source are not deleted.
+// It returns the name of the new index
+func (i *Client) Migrate(m *Migration) (string, error) {
+	return i.MigrateContext(context.Background(), m)
+}
+
+// MigrateContext is the context-aware version of Migrate
+func (i *Client) MigrateContext(ctx context.Context, m *Migration) (string, error) {
+	if m.Schema == nil {
+		return "", errors.New("Migrate: a schema is required")
+	}
+	if m.Source != nil && m.Schema.Definition != nil && m.Schema.Definition.IndexOn == JSONIndex {
+		return "", errors.New("Migrate: document sources are not supported on JSON indexes")
+	}
+
+	alias := i.name
+	var current string
+	info, err := i.InfoContext(ctx)
+	if err == nil {
+		current = info.Name
+		if current == alias {
+			return "", fmt.Errorf("Migrate: %s is an index, not an alias", alias)
+		}
+	} else if !isUnknownIndexError(err) {
+		return "", err
+	}
+
+	version := m.Version
+	if version == 0 {
+		version = nextIndexVersion(alias, current)
+	}
+	name := alias + "_v" + strconv.Itoa(version)
+	next := NewClientFromPools(i.pool, i.readPool, name)
+	if err := next.CreateIndexContext(ctx, m.Schema); err != nil {
+		return "", err
+	}
+
+	if err := next.migrate(ctx, m, alias, current); err != nil {
+		// the context may be over already
+		if dropErr := next.DropIndex(false); dropErr != nil {
+			return "", fmt.Errorf("%v, and dropping %s failed: %v", err, name, dropErr)
+		}
+		return "", err
+	}
+
+	if m.DropOld && current != "" {
+		if err := NewClientFromPools(i.pool, i.readPool, current).DropIndexContext(ctx, false); err != nil {
+			return name, fmt.Errorf("Migrate: the alias points to %s, but dropping %s failed: %v", name, current, err)
+		}
+	}
+	return name, nil
+}
+
+// migrate backfills the new index and points the alias to it
+func (i *Client) migrate(ctx context.Context, m *Migration, alias, current string) error {
+	if m.Source != nil {
+		if err := i.backfill(ctx, m); err != nil {
+			return err
+		}
+	}
+	if err := i.waitIndexing(ctx, m.PollInterval); err != nil {
+		return err
+	}
+	if current == "" {
+		return i.AliasAddContext(ctx, alias)
+	}
+	return i.AliasUpdateContext(ctx, alias)
+}
+
+// backfill writes the documents of the source of the migration
+func (i *Client) backfill(ctx context.Context, m *Migration) error {
+	var w *HashWriter
+	if m.Schema.Definition != nil {
+		w = NewHashWriter(i, m.Schema.Definition)
+	}
+	// documents already written for the previous index are replaced
+	opts := IndexingOptions{Replace: true}
+	for {
+		docs, err := m.Source(ctx)
+		if err != nil || len(docs) == 0 {
+			return err
+		}
+		if w != nil {
+			err = w.IndexOptionsContext(ctx, opts, docs...)
+		} else {
+			err = i.IndexOptionsContext(ctx, opts, docs...)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// waitIndexing waits for RediSearch to finish scanning the keys followed by the index
+func (i *Client) waitIndexing(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		interval = 100 * time.Millisecond
+	}
+	for {
+		info, err := i.InfoContext(ctx)
+		if err != nil {
+			return err
+		}
+		if info.Indexing == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// nextIndexVersion returns the version following the one of the current index of the alias, 1 if it has none
+func nextIndexVersion(alias, current string) int {
+	if !strings.HasPrefix(current, alias+"_v") {
+		return 1
+	}
+	version, err := strconv.Atoi(strings.TrimPrefix(current, alias+"_v"))
+	if err != nil {
+		return 1
+	}
+	return version + 1
+}
+
+// isUnknownIndexError returns true if err is the reply of RediSearch to a missing index
+func isUnknownIndexError(err error) bool {
+	rerr, ok := err.(redis.Error)
+	if !ok {
+		return false
+	}
+	msg := strings.ToLower(rerr.Error())
+	return strings.Contains(msg, "unknown index name") || strings.Contains(msg, "no such index")
+}
@@ -0,0 +1,128 @@
+package redisearch
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Migrate(t *testing.T) {
+	schema := NewSchema(DefaultOptions).AddField(NewTextField("title")).
+		SetIndexDefinition(NewIndexDefinition().AddPrefix("product:"))
+	batches := [][]Document{{NewDocument("product:1", 1).Set("title", "hello")}}
+	source := func(ctx context.Context) ([]Document, error) {
+		if len(batches) == 0 {
+			return nil, nil
+		}
+		docs := batches[0]
+		batches = batches[1:]
+		return docs, nil
+	}
+
+	conn := &scriptedConn{replies: []interface{}{
+		[]interface{}{"index_name", []byte("products_v6")},
+		"OK",
+		"hash", "OK",
+		[]interface{}{"index_name", []byte("products_v7"), "indexing", int64(1)},
+		[]interface{}{"index_name", []byte("products_v7"), "indexing", int64(0)},
+		"OK",
+		"OK",
+	}}
+	c := NewClientFromPool(&scriptedPool{conn: conn}, "products")
+	m := NewMigration(schema).SetSource(source).SetDropOld(true).SetPollInterval(time.Millisecond)
+	name, err := c.Migrate(m)
+	assert.Nil(t, err)
+	assert.Equal(t, "products_v7", name)
+	assert.Equal(t, []string{
+		"FT.INFO products",
+		"FT.CREATE products_v7 ON HASH PREFIX 1 product: SCHEMA title TEXT",
+		strings.TrimSpace("SCRIPT LOAD " + hashWriteScriptSrc),
+		"EVALSHA " + hashWriteScript.Hash() + " 1 product:1 replace 0 title hello __score 1",
+		"FT.INFO products_v7",
+		"FT.INFO products_v7",
+		"FT.ALIASUPDATE products products_v7",
+		"FT.DROPINDEX products_v6",
+	}, conn.commands)
+}
+
+func TestClient_Migrate_newAlias(t *testing.T) {
+	conn := &scriptedConn{replies: []interface{}{
+		redis.Error("Unknown Index name"),
+		"OK",
+		[]interface{}{"index_name", []byte("products_v1")},
+		"OK",
+	}}
+	c := NewClientFromPool(&scriptedPool{conn: conn}, "products")
+	name, err := c.Migrate(NewMigration(NewSchema(DefaultOptions).AddField(NewTextField("title"))).SetDropOld(true))
+	assert.Nil(t, err)
+	assert.Equal(t, "products_v1", name)
+	assert.Equal(t, []string{
+		"FT.INFO products",
+		"FT.CREATE products_v1 SCHEMA title TEXT",
+		"FT.INFO products_v1",
+		"FT.ALIASADD products products_v1",
+	}, conn.commands)
+}
+
+func TestClient_Migrate_rollback(t *testing.T) {
+	schema := NewSchema(DefaultOptions).AddField(NewTextField("title"))
+
+	conn := &scriptedConn{replies: []interface{}{
+		[]interface{}{"index_name", []byte("products_v2")},
+		"OK",
+		[]interface{}{"index_name", []byte("products_v9")},
+		redis.Error("Unknown alias"),
+		"OK",
+	}}
+	c := NewClientFromPool(&scriptedPool{conn: conn}, "products")
+	_, err := c.Migrate(NewMigration(schema).SetVersion(9).SetDropOld(true))
+	assert.Equal(t, redis.Error("Unknown alias"), err)
+	assert.Equal(t, []string{
+		"FT.INFO products",
+		"FT.CREATE products_v9 SCHEMA title TEXT",
+		"FT.INFO products_v9",
+		"FT.ALIASUPDATE products products_v9",
+		"FT.DROPINDEX products_v9",
+	}, conn.commands)
+
+	// the new index is not dropped if it could not be created
+	conn = &scriptedConn{replies: []interface{}{
+		[]interface{}{"index_name", []byte("products_v2")},
+		redis.Error("Index already exists"),
+	}}
+	c = NewClientFromPool(&scriptedPool{conn: conn}, "products")
+	_, err = c.Migrate(NewMigration(schema))
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, len(conn.commands))
+
+	// the alias is required
+	conn = &scriptedConn{replies: []interface{}{[]interface{}{"index_name", []byte("products")}}}
+	c = NewClientFromPool(&scriptedPool{conn: conn}, "products")
+	_, err = c.Migrate(NewMigration(schema))
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, len(conn.commands))
+}
+
+func Test_nextIndexVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		want    int
+	}{
+		{"none", "", 1},
+		{"versioned", "products_v6", 7},
+		{"other", "products_old", 1},
+		{"other-alias", "items_v3", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextIndexVersion("products", tt.current); got != tt.want {
+				t.Errorf("nextIndexVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
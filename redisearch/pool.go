@@ -1,8 +1,9 @@
 package redisearch
 
 import (
-	"math/rand"
+	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 	"github.com/gomodule/redigo/redis"
 )
@@ -11,57 +12,305 @@ type ConnPool interface {
 	Get() redis.Conn
 }
 
+// ContextConnPool is a ConnPool that can wait for an available connection
+// within the deadline of a context. The context-aware Client methods use it when the pool implements it
+type ContextConnPool interface {
+	ConnPool
+	GetContext(ctx context.Context) (redis.Conn, error)
+}
+
 type SingleHostPool struct {
 	*redis.Pool
 }
 
-func NewSingleHostPool(host string) *SingleHostPool {
-	ret := redis.NewPool(func() (redis.Conn, error) {
-		// TODO: Add timeouts. and 2 separate pools for indexing and querying, with different timeouts
-		return redis.Dial("tcp", host)
-	}, maxConns)
-	ret.TestOnBorrow = func(c redis.Conn, t time.Time) (err error) {
-		if time.Since(t) > time.Second {
-			_, err = c.Do("PING")
-		}
-		return err
-	}
-	return &SingleHostPool{ret}
+// NewSingleHostPool creates a pool of connections to host, given as host:port or as a redis:// or rediss:// URL
+func NewSingleHostPool(host string, opts ...ClientOption) *SingleHostPool {
+	return &SingleHostPool{newClientOptions(opts).newPool(host)}
 }
 
+// MultiHostPool is a pool of connections to several hosts. The connections are taken from the healthy hosts,
+// picked by a Balancer, at random by default. A host failing to give a connection, or failing the health checks
+// enabled by WithHealthCheck, is ejected for a delay doubling at each consecutive failure, see WithEjection
 type MultiHostPool struct {
+	// readmitAt is the time in unix nanoseconds the next ejected host is re-admitted at, 0 if none.
+	// It comes first for the alignment of atomic operations
+	readmitAt int64
+
 	sync.Mutex
-	pools map[string]*redis.Pool
-	hosts []string
+	hosts   []string
+	options *clientOptions
+	all     []*PoolHost
+
+	// healthy holds the []*PoolHost which are not ejected, loaded by Get without locking
+	healthy atomic.Value
+
+	stop   chan struct{}
+	closed bool
+
+	// now returns the current time
+	now func() time.Time
 }
 
-func NewMultiHostPool(hosts []string) *MultiHostPool {
+// PoolHost is a host of a MultiHostPool, as seen by a Balancer
+type PoolHost struct {
+	// latency is the moving average of the round trip time of the health checks, in nanoseconds
+	latency int64
+
+	// ejectedUntil is the time in unix nanoseconds the host is ejected until, guarded by the lock of the pool
+	ejectedUntil int64
+
+	// failures is the number of consecutive failures, changed with the lock of the pool
+	failures int32
 
-	return &MultiHostPool{
-		pools: make(map[string]*redis.Pool, len(hosts)),
-		hosts: hosts,
+	addr string
+	pool hostPool
+}
+
+// hostPool is the pool of the connections to a host, a redis.Pool
+type hostPool interface {
+	ContextConnPool
+	ActiveCount() int
+	IdleCount() int
+	Close() error
+}
+
+// NewMultiHostPool creates a pool of connections to several hosts, given as host:port or as redis:// or rediss:// URLs
+func NewMultiHostPool(hosts []string, opts ...ClientOption) *MultiHostPool {
+	o := newClientOptions(opts)
+	p := newMultiHostPool(hosts, o, func(addr string) hostPool {
+		return o.newPool(addr)
+	})
+	if o.healthCheckInterval > 0 {
+		go p.healthChecks(o.healthCheckInterval)
 	}
+	return p
 }
 
+func newMultiHostPool(hosts []string, o *clientOptions, newPool func(addr string) hostPool) *MultiHostPool {
+	p := &MultiHostPool{
+		hosts:   hosts,
+		options: o,
+		stop:    make(chan struct{}),
+		now:     time.Now,
+	}
+	for _, addr := range hosts {
+		p.all = append(p.all, &PoolHost{addr: addr, pool: newPool(addr)})
+	}
+	p.healthy.Store(p.all)
+	return p
+}
+
+// Get gets a connection from a healthy host. Hosts failing to give a connection are ejected, and another one is tried
 func (p *MultiHostPool) Get() redis.Conn {
+	conn, _ := p.get(nil)
+	return conn
+}
+
+// GetContext gets a connection from a healthy host, waiting for it within the deadline of ctx
+func (p *MultiHostPool) GetContext(ctx context.Context) (redis.Conn, error) {
+	return p.get(ctx)
+}
+
+// get gets a connection, with GetContext if ctx is not nil
+func (p *MultiHostPool) get(ctx context.Context) (conn redis.Conn, err error) {
+	hosts := p.available()
+	for {
+		ii := p.options.balancer.Pick(hosts)
+		h := hosts[ii]
+		if ctx != nil {
+			conn, err = h.pool.GetContext(ctx)
+		} else {
+			conn = h.pool.Get()
+			err = conn.Err()
+		}
+		// an exhausted pool or a canceled context are no failures of the host
+		if err == nil || err == redis.ErrPoolExhausted || (ctx != nil && ctx.Err() != nil) {
+			if err == nil && atomic.LoadInt32(&h.failures) > 0 {
+				p.markUp(h)
+			}
+			return
+		}
+		p.markDown(h)
+		if len(hosts) == 1 {
+			return
+		}
+		if conn != nil {
+			conn.Close()
+		}
+		hosts = append(append([]*PoolHost{}, hosts[:ii]...), hosts[ii+1:]...)
+	}
+}
+
+// available returns the hosts connections are taken from: the healthy ones, or all of them if all are ejected
+func (p *MultiHostPool) available() []*PoolHost {
+	if at := atomic.LoadInt64(&p.readmitAt); at != 0 && p.now().UnixNano() >= at {
+		p.Lock()
+		p.update()
+		p.Unlock()
+	}
+	hosts := p.healthy.Load().([]*PoolHost)
+	if len(hosts) == 0 {
+		return p.all
+	}
+	return hosts
+}
+
+// update re-admits the hosts whose ejection is over, and stores the healthy hosts. It must be called with the lock held
+func (p *MultiHostPool) update() {
+	now := p.now().UnixNano()
+	var healthy []*PoolHost
+	var readmitAt int64
+	for _, h := range p.all {
+		if h.ejectedUntil <= now {
+			healthy = append(healthy, h)
+		} else if readmitAt == 0 || h.ejectedUntil < readmitAt {
+			readmitAt = h.ejectedUntil
+		}
+	}
+	p.healthy.Store(healthy)
+	atomic.StoreInt64(&p.readmitAt, readmitAt)
+}
+
+// markDown ejects a failing host, for a delay doubling at each consecutive failure
+func (p *MultiHostPool) markDown(h *PoolHost) {
+	delay, maxDelay := p.options.ejectBase, p.options.ejectMax
+	if delay <= 0 {
+		return
+	}
 	p.Lock()
 	defer p.Unlock()
-	host := p.hosts[rand.Intn(len(p.hosts))]
-	pool, found := p.pools[host]
-	if !found {
-		pool = redis.NewPool(func() (redis.Conn, error) {
-			// TODO: Add timeouts. and 2 separate pools for indexing and querying, with different timeouts
-			return redis.Dial("tcp", host)
-		}, maxConns)
-		pool.TestOnBorrow = func(c redis.Conn, t time.Time) (err error) {
-			if time.Since(t) > time.Second {
-				_, err = c.Do("PING")
-			}
-			return err
+	now := p.now()
+	if h.ejectedUntil > now.UnixNano() {
+		// ejected already, i.e. by a concurrent Get
+		return
+	}
+	failures := atomic.AddInt32(&h.failures, 1)
+	for ii := int32(1); ii < failures && delay < maxDelay; ii++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	h.ejectedUntil = now.Add(delay).UnixNano()
+	p.update()
+}
+
+// markUp resets the failures of a host which succeeded
+func (p *MultiHostPool) markUp(h *PoolHost) {
+	p.Lock()
+	defer p.Unlock()
+	atomic.StoreInt32(&h.failures, 0)
+	if h.ejectedUntil != 0 {
+		h.ejectedUntil = 0
+		p.update()
+	}
+}
+
+// healthChecks checks the health of the hosts at every interval, until the pool is closed
+func (p *MultiHostPool) healthChecks(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.checkHealth(interval)
 		}
+	}
+}
+
+// checkHealth sends PING to the hosts which are not ejected, within timeout. Hosts which fail are ejected,
+// and the round trip times of the others update their latency
+func (p *MultiHostPool) checkHealth(timeout time.Duration) {
+	now := p.now().UnixNano()
+	var wg sync.WaitGroup
+	for _, h := range p.all {
+		p.Lock()
+		ejected := h.ejectedUntil > now
+		p.Unlock()
+		if ejected {
+			continue
+		}
+		wg.Add(1)
+		go func(h *PoolHost) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			start := p.now()
+			conn, err := h.pool.GetContext(ctx)
+			if err == redis.ErrPoolExhausted {
+				return
+			}
+			if err == nil {
+				_, err = doContext(ctx, conn, "PING")
+				conn.Close()
+			}
+			if err != nil {
+				p.markDown(h)
+				return
+			}
+			h.observeLatency(p.now().Sub(start))
+			if atomic.LoadInt32(&h.failures) > 0 {
+				p.markUp(h)
+			}
+		}(h)
+	}
+	wg.Wait()
+}
+
+// Close stops the health checks and closes the pools of the hosts
+func (p *MultiHostPool) Close() error {
+	p.Lock()
+	if p.closed {
+		p.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.stop)
+	p.Unlock()
 
-		p.pools[host] = pool
+	var err error
+	for _, h := range p.all {
+		if e := h.pool.Close(); e != nil && err == nil {
+			err = e
+		}
 	}
-	return pool.Get()
+	return err
+}
+
+// Addr returns the address of the host
+func (h *PoolHost) Addr() string {
+	return h.addr
+}
 
+// ActiveCount returns the number of connections to the host, in use or idle
+func (h *PoolHost) ActiveCount() int {
+	return h.pool.ActiveCount()
+}
+
+// IdleCount returns the number of idle connections to the host
+func (h *PoolHost) IdleCount() int {
+	return h.pool.IdleCount()
+}
+
+// Latency returns the moving average of the round trip time of the health checks, 0 until measured
+func (h *PoolHost) Latency() time.Duration {
+	return time.Duration(atomic.LoadInt64(&h.latency))
+}
+
+// Failures returns the number of consecutive failures of the host
+func (h *PoolHost) Failures() int {
+	return int(atomic.LoadInt32(&h.failures))
+}
+
+// observeLatency adds the round trip time of a health check to the moving average of the latency
+func (h *PoolHost) observeLatency(rtt time.Duration) {
+	latency := atomic.LoadInt64(&h.latency)
+	if latency == 0 {
+		latency = int64(rtt)
+	} else {
+		latency += (int64(rtt) - latency) / 4
+	}
+	atomic.StoreInt64(&h.latency, latency)
 }
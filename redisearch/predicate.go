@@ -1,5 +1,14 @@
 package redisearch
 
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gomodule/redigo/redis"
+)
+
 type Operator string
 
 const (
@@ -12,9 +21,10 @@ const (
 	Lte Operator = "<="
 
 	Between          Operator = "BETWEEN"
-	BetweenInclusive Operator = "BETWEEEN_EXCLUSIVE"
+	BetweenInclusive Operator = "BETWEEN_INCLUSIVE"
 )
 
+// Predicate is a numeric filter on a single property, serialized as a FILTER clause
 type Predicate struct {
 	Property string
 	Operator Operator
@@ -33,6 +43,8 @@ func Equals(property string, value interface{}) Predicate {
 
 }
 
+// InRange creates a predicate matching values between min and max.
+// If inclusive is false both bounds are exclusive
 func InRange(property string, min, max interface{}, inclusive bool) Predicate {
 	operator := Between
 	if inclusive {
@@ -57,3 +69,123 @@ func GreaterThan(property string, value interface{}) Predicate {
 func GreaterThanEquals(property string, value interface{}) Predicate {
 	return NewPredicate(property, Gte, value)
 }
+
+// bounds returns the min and max arguments of the numeric range matched by the predicate
+func (p Predicate) bounds() (min, max string, err error) {
+	arity := 1
+	if p.Operator == Between || p.Operator == BetweenInclusive {
+		arity = 2
+	}
+	if len(p.Value) != arity {
+		return "", "", fmt.Errorf("predicate on %s: operator %s expects %d value(s), got %d", p.Property, p.Operator, arity, len(p.Value))
+	}
+
+	switch p.Operator {
+	case Eq:
+		if min, err = formatRangeValue(p.Value[0], false); err == nil {
+			max = min
+		}
+	case Gt:
+		min, err = formatRangeValue(p.Value[0], true)
+		max = "+inf"
+	case Gte:
+		min, err = formatRangeValue(p.Value[0], false)
+		max = "+inf"
+	case Lt:
+		min = "-inf"
+		max, err = formatRangeValue(p.Value[0], true)
+	case Lte:
+		min = "-inf"
+		max, err = formatRangeValue(p.Value[0], false)
+	case Between, BetweenInclusive:
+		exclusive := p.Operator == Between
+		if min, err = formatRangeValue(p.Value[0], exclusive); err == nil {
+			max, err = formatRangeValue(p.Value[1], exclusive)
+		}
+	default:
+		return "", "", fmt.Errorf("predicate on %s: unsupported operator %q", p.Property, p.Operator)
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("predicate on %s: %s", p.Property, err)
+	}
+	return
+}
+
+// serialize returns the FILTER clause of the predicate, as used by FT.SEARCH
+func (p Predicate) serialize() (redis.Args, error) {
+	min, max, err := p.bounds()
+	if err != nil {
+		return nil, err
+	}
+	return redis.Args{"FILTER", p.Property, min, max}, nil
+}
+
+// queryExpr returns the predicate as a numeric range query expression, e.g. `@price:[10 (20]`.
+// FT.AGGREGATE does not accept FILTER clauses, so predicates are merged into the query string instead
+func (p Predicate) queryExpr() (string, error) {
+	min, max, err := p.bounds()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("@%s:[%s %s]", p.Property, min, max), nil
+}
+
+// formatRangeValue converts a numeric predicate value to a range bound,
+// prefixing it with "(" if the bound is exclusive
+func formatRangeValue(value interface{}, exclusive bool) (string, error) {
+	var s string
+	switch v := value.(type) {
+	case int:
+		s = strconv.FormatInt(int64(v), 10)
+	case int8:
+		s = strconv.FormatInt(int64(v), 10)
+	case int16:
+		s = strconv.FormatInt(int64(v), 10)
+	case int32:
+		s = strconv.FormatInt(int64(v), 10)
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case uint:
+		s = strconv.FormatUint(uint64(v), 10)
+	case uint8:
+		s = strconv.FormatUint(uint64(v), 10)
+	case uint16:
+		s = strconv.FormatUint(uint64(v), 10)
+	case uint32:
+		s = strconv.FormatUint(uint64(v), 10)
+	case uint64:
+		s = strconv.FormatUint(v, 10)
+	case float32:
+		return formatRangeFloat(float64(v), 32, exclusive)
+	case float64:
+		return formatRangeFloat(v, 64, exclusive)
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid numeric value %q", v)
+		}
+		return formatRangeFloat(f, 64, exclusive)
+	default:
+		return "", fmt.Errorf("unsupported value type %T", value)
+	}
+	if exclusive {
+		s = "(" + s
+	}
+	return s, nil
+}
+
+func formatRangeFloat(f float64, bitSize int, exclusive bool) (string, error) {
+	switch {
+	case math.IsNaN(f):
+		return "", fmt.Errorf("invalid numeric value NaN")
+	case math.IsInf(f, 1):
+		return "+inf", nil
+	case math.IsInf(f, -1):
+		return "-inf", nil
+	}
+	s := strconv.FormatFloat(f, 'f', -1, bitSize)
+	if exclusive {
+		s = "(" + s
+	}
+	return s, nil
+}
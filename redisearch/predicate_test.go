@@ -0,0 +1,45 @@
+package redisearch
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+func TestPredicate_serialize(t *testing.T) {
+	tests := []struct {
+		name      string
+		predicate Predicate
+		want      redis.Args
+		wantErr   bool
+	}{
+		{"equals", Equals("price", 10), redis.Args{"FILTER", "price", "10", "10"}, false},
+		{"greater-than", GreaterThan("price", 10), redis.Args{"FILTER", "price", "(10", "+inf"}, false},
+		{"greater-than-equals", GreaterThanEquals("price", 10), redis.Args{"FILTER", "price", "10", "+inf"}, false},
+		{"less-than", LessThan("price", 2.5), redis.Args{"FILTER", "price", "-inf", "(2.5"}, false},
+		{"less-than-equals", LessThanEquals("price", float32(2.1)), redis.Args{"FILTER", "price", "-inf", "2.1"}, false},
+		{"in-range-inclusive", InRange("price", int64(-5), uint(5), true), redis.Args{"FILTER", "price", "-5", "5"}, false},
+		{"in-range-exclusive", InRange("price", 1, 5, false), redis.Args{"FILTER", "price", "(1", "(5"}, false},
+		{"infinity", InRange("price", math.Inf(-1), math.Inf(1), false), redis.Args{"FILTER", "price", "-inf", "+inf"}, false},
+		{"numeric-string", InRange("price", "-inf", "100", true), redis.Args{"FILTER", "price", "-inf", "100"}, false},
+		{"invalid-string", Equals("price", "ten"), nil, true},
+		{"invalid-type", Equals("price", []int{1}), nil, true},
+		{"nan", Equals("price", math.NaN()), nil, true},
+		{"missing-value", NewPredicate("price", Between, 1), nil, true},
+		{"unknown-operator", NewPredicate("price", Operator("~"), 1), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.predicate.serialize()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("serialize() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("serialize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
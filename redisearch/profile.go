@@ -0,0 +1,275 @@
+package redisearch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Profile is the execution profile of a query returned by FT.PROFILE. Times are in milliseconds
+type Profile struct {
+	TotalTime            float64
+	ParsingTime          float64
+	PipelineCreationTime float64
+
+	// Iterators is the tree of the iterators reading the index, nil if not reported
+	Iterators *IteratorProfile
+
+	// ResultProcessors are the steps of the pipeline processing the results, in order
+	ResultProcessors []ResultProcessorProfile
+}
+
+// IteratorProfile is the profile of an iterator of the index, and of its children
+type IteratorProfile struct {
+	// Type is the type of the iterator, i.e. TEXT, INTERSECT, UNION, NUMERIC, TAG
+	Type string
+
+	// QueryType is the kind of query node of union iterators, i.e. UNION or FUZZY
+	QueryType string
+
+	// Term is the term read by TEXT and TAG iterators
+	Term string
+
+	Time float64
+
+	// Counter is the number of times the iterator was read
+	Counter int64
+
+	// Size is the number of documents of the iterator
+	Size int64
+
+	Children []*IteratorProfile
+}
+
+// ResultProcessorProfile is the profile of a result processor, i.e. Index, Scorer, Sorter or Loader
+type ResultProcessorProfile struct {
+	Type    string
+	Time    float64
+	Counter int64
+}
+
+// ProfileSearch runs a search like Search does, returning the execution profile of the query as well
+func (i *Client) ProfileSearch(q *Query) (docs []Document, total int, profile *Profile, err error) {
+	return i.ProfileSearchContext(context.Background(), q)
+}
+
+// ProfileSearchContext is the context-aware version of ProfileSearch
+func (i *Client) ProfileSearchContext(ctx context.Context, q *Query) (docs []Document, total int, profile *Profile, err error) {
+	queryArgs, err := q.serialize()
+	if err != nil {
+		return
+	}
+	res, profile, err := i.profile(ctx, "SEARCH", queryArgs)
+	if err != nil {
+		return
+	}
+	docs, total, err = loadSearchReply(q, res)
+	return
+}
+
+// ProfileAggregate runs an aggregation like Aggregate does, returning the execution profile of the query as well.
+// Cursors are not supported
+func (i *Client) ProfileAggregate(q *AggregateQuery) (aggregateReply [][]string, total int, profile *Profile, err error) {
+	return i.ProfileAggregateContext(context.Background(), q)
+}
+
+// ProfileAggregateContext is the context-aware version of ProfileAggregate
+func (i *Client) ProfileAggregateContext(ctx context.Context, q *AggregateQuery) (aggregateReply [][]string, total int, profile *Profile, err error) {
+	if q.WithCursor {
+		err = errors.New("ProfileAggregate: cursors are not supported")
+		return
+	}
+	queryArgs, err := q.serialize()
+	if err != nil {
+		return
+	}
+	res, profile, err := i.profile(ctx, "AGGREGATE", queryArgs)
+	if err != nil {
+		return
+	}
+	total, aggregateReply, err = processAggReply(res)
+	return
+}
+
+// profile issues FT.PROFILE and returns the results and the parsed profile
+func (i *Client) profile(ctx context.Context, command string, queryArgs redis.Args) ([]interface{}, *Profile, error) {
+	conn, err := i.getReadConn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer conn.Close()
+
+	args := redis.Args{i.name, command, "QUERY"}
+	args = append(args, queryArgs...)
+	res, err := redis.Values(doContext(ctx, conn, "FT.PROFILE", args...))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(res) != 2 {
+		return nil, nil, fmt.Errorf("Invalid profile reply size: %d", len(res))
+	}
+	results, err := redis.Values(res[0], nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	profile, err := loadProfile(res[1])
+	if err != nil {
+		return nil, nil, err
+	}
+	return results, profile, nil
+}
+
+// loadProfile parses the profile section of the reply of FT.PROFILE, a list of [name, value...] entries
+func loadProfile(reply interface{}) (*Profile, error) {
+	entries, err := redis.Values(reply, nil)
+	if err != nil {
+		return nil, err
+	}
+	p := &Profile{}
+	for _, e := range entries {
+		entry, err := redis.Values(e, nil)
+		if err != nil || len(entry) < 2 {
+			continue
+		}
+		name, _ := redis.String(entry[0], nil)
+		switch name {
+		case "Total profile time":
+			p.TotalTime = profileFloat(entry[1])
+		case "Parsing time":
+			p.ParsingTime = profileFloat(entry[1])
+		case "Pipeline creation time":
+			p.PipelineCreationTime = profileFloat(entry[1])
+		case "Iterators profile":
+			p.Iterators = loadIteratorProfile(entry[1])
+		case "Result processors profile":
+			for _, rp := range entry[1:] {
+				p.ResultProcessors = append(p.ResultProcessors, loadResultProcessorProfile(rp))
+			}
+		}
+	}
+	return p, nil
+}
+
+// loadIteratorProfile parses the key/value pairs of an iterator profile, followed by its children
+func loadIteratorProfile(reply interface{}) *IteratorProfile {
+	values, err := redis.Values(reply, nil)
+	if err != nil || len(values) == 0 {
+		return nil
+	}
+	it := &IteratorProfile{}
+	for ii := 0; ii+1 < len(values); ii += 2 {
+		key, _ := redis.String(values[ii], nil)
+		switch key {
+		case "Type":
+			it.Type, _ = redis.String(values[ii+1], nil)
+		case "Query type":
+			it.QueryType, _ = redis.String(values[ii+1], nil)
+		case "Term":
+			it.Term, _ = redis.String(values[ii+1], nil)
+		case "Time":
+			it.Time = profileFloat(values[ii+1])
+		case "Counter":
+			it.Counter, _ = redis.Int64(values[ii+1], nil)
+		case "Size":
+			it.Size, _ = redis.Int64(values[ii+1], nil)
+		case "Child iterator", "Child iterators":
+			// the children follow, either one per value or as a single list
+			for _, child := range values[ii+1:] {
+				children, _ := redis.Values(child, nil)
+				if len(children) > 0 {
+					if _, nested := children[0].([]interface{}); nested {
+						for _, c := range children {
+							it.addChild(loadIteratorProfile(c))
+						}
+						continue
+					}
+				}
+				it.addChild(loadIteratorProfile(child))
+			}
+			return it
+		}
+	}
+	return it
+}
+
+func (it *IteratorProfile) addChild(child *IteratorProfile) {
+	if child != nil {
+		it.Children = append(it.Children, child)
+	}
+}
+
+// loadResultProcessorProfile parses the key/value pairs of a result processor profile
+func loadResultProcessorProfile(reply interface{}) ResultProcessorProfile {
+	values, _ := redis.Values(reply, nil)
+	rp := ResultProcessorProfile{}
+	for ii := 0; ii+1 < len(values); ii += 2 {
+		key, _ := redis.String(values[ii], nil)
+		switch key {
+		case "Type":
+			rp.Type, _ = redis.String(values[ii+1], nil)
+		case "Time":
+			rp.Time = profileFloat(values[ii+1])
+		case "Counter":
+			rp.Counter, _ = redis.Int64(values[ii+1], nil)
+		}
+	}
+	return rp
+}
+
+// profileFloat parses a time of the profile, replied either as a string or as an integer
+func profileFloat(reply interface{}) float64 {
+	if n, ok := reply.(int64); ok {
+		return float64(n)
+	}
+	f, _ := redis.Float64(reply, nil)
+	return f
+}
+
+// String formats the profile as an indented tree, to be logged, i.e.
+//
+//	Total time: 0.231ms, parsing: 0.028ms, pipeline creation: 0.011ms
+//	Iterators:
+//	  INTERSECT time=0.12ms counter=2
+//	    TEXT hello time=0.05ms counter=3 size=3
+//	    TEXT world time=0.04ms counter=2 size=2
+//	Result processors:
+//	  Index time=0.13ms counter=2
+//	  Scorer time=0.02ms counter=2
+func (p *Profile) String() string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "Total time: %gms, parsing: %gms, pipeline creation: %gms\n", p.TotalTime, p.ParsingTime, p.PipelineCreationTime)
+	if p.Iterators != nil {
+		b.WriteString("Iterators:\n")
+		p.Iterators.write(&b, 1)
+	}
+	if len(p.ResultProcessors) > 0 {
+		b.WriteString("Result processors:\n")
+		for _, rp := range p.ResultProcessors {
+			fmt.Fprintf(&b, "  %s time=%gms counter=%d\n", rp.Type, rp.Time, rp.Counter)
+		}
+	}
+	return b.String()
+}
+
+// write writes the iterator and its children, indented by depth
+func (it *IteratorProfile) write(b *bytes.Buffer, depth int) {
+	b.WriteString(strings.Repeat("  ", depth) + it.Type)
+	if it.Term != "" {
+		b.WriteString(" " + it.Term)
+	}
+	if it.QueryType != "" && it.QueryType != it.Type {
+		b.WriteString(" (" + it.QueryType + ")")
+	}
+	fmt.Fprintf(b, " time=%gms counter=%d", it.Time, it.Counter)
+	if it.Size != 0 {
+		fmt.Fprintf(b, " size=%d", it.Size)
+	}
+	b.WriteString("\n")
+	for _, child := range it.Children {
+		child.write(b, depth+1)
+	}
+}
@@ -0,0 +1,102 @@
+package redisearch
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func profileReply(iterators []interface{}) []interface{} {
+	return []interface{}{
+		[]interface{}{[]byte("Total profile time"), []byte("0.5")},
+		[]interface{}{[]byte("Parsing time"), []byte("0.25")},
+		[]interface{}{[]byte("Pipeline creation time"), int64(0)},
+		[]interface{}{[]byte("Iterators profile"), iterators},
+		[]interface{}{[]byte("Result processors profile"),
+			[]interface{}{[]byte("Type"), []byte("Index"), []byte("Time"), []byte("0.125"), []byte("Counter"), int64(2)},
+			[]interface{}{[]byte("Type"), []byte("Scorer"), []byte("Time"), []byte("0.0625"), []byte("Counter"), int64(2)},
+		},
+	}
+}
+
+func Test_loadProfile(t *testing.T) {
+	hello := []interface{}{[]byte("Type"), []byte("TEXT"), []byte("Term"), []byte("hello"), []byte("Time"), []byte("0.01"),
+		[]byte("Counter"), int64(3), []byte("Size"), int64(3)}
+	world := []interface{}{[]byte("Type"), []byte("TEXT"), []byte("Term"), []byte("world"), []byte("Time"), []byte("0.02"),
+		[]byte("Counter"), int64(2), []byte("Size"), int64(2)}
+	want := &Profile{
+		TotalTime:   0.5,
+		ParsingTime: 0.25,
+		Iterators: &IteratorProfile{Type: "INTERSECT", Time: 0.125, Counter: 2, Children: []*IteratorProfile{
+			{Type: "TEXT", Term: "hello", Time: 0.01, Counter: 3, Size: 3},
+			{Type: "TEXT", Term: "world", Time: 0.02, Counter: 2, Size: 2},
+		}},
+		ResultProcessors: []ResultProcessorProfile{
+			{Type: "Index", Time: 0.125, Counter: 2},
+			{Type: "Scorer", Time: 0.0625, Counter: 2},
+		},
+	}
+	intersect := []interface{}{[]byte("Type"), []byte("INTERSECT"), []byte("Time"), []byte("0.125"), []byte("Counter"), int64(2)}
+	tests := []struct {
+		name      string
+		iterators []interface{}
+	}{
+		{"children-values", append(append([]interface{}{}, intersect...), []byte("Child iterators"), hello, world)},
+		{"children-list", append(append([]interface{}{}, intersect...), []byte("Child iterators"), []interface{}{hello, world})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadProfile(profileReply(tt.iterators))
+			assert.Nil(t, err)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("loadProfile() = %v, want %v", got, want)
+			}
+		})
+	}
+
+	assert.Equal(t, "Total time: 0.5ms, parsing: 0.25ms, pipeline creation: 0ms\n"+
+		"Iterators:\n"+
+		"  INTERSECT time=0.125ms counter=2\n"+
+		"    TEXT hello time=0.01ms counter=3 size=3\n"+
+		"    TEXT world time=0.02ms counter=2 size=2\n"+
+		"Result processors:\n"+
+		"  Index time=0.125ms counter=2\n"+
+		"  Scorer time=0.0625ms counter=2\n", want.String())
+}
+
+func TestClient_ProfileSearch(t *testing.T) {
+	iterators := []interface{}{[]byte("Type"), []byte("TEXT"), []byte("Term"), []byte("hello")}
+	conn := &scriptedConn{replies: []interface{}{
+		[]interface{}{
+			[]interface{}{int64(1), []byte("doc1"), []interface{}{[]byte("title"), []byte("hello")}},
+			profileReply(iterators),
+		},
+		[]interface{}{
+			[]interface{}{int64(1), []interface{}{[]byte("title"), []byte("hello")}},
+			profileReply(iterators),
+		},
+	}}
+	c := NewClientFromPool(&scriptedPool{conn: conn}, "idx")
+
+	docs, total, profile, err := c.ProfileSearch(NewQuery("hello").Limit(0, 1))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "doc1", docs[0].Id)
+	assert.Equal(t, "hello", docs[0].Properties["title"])
+	assert.Equal(t, "hello", profile.Iterators.Term)
+
+	rows, total, profile, err := c.ProfileAggregate(NewAggregateQuery().SetQuery(NewQuery("hello")).Load([]string{"title"}))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, [][]string{{"title", "hello"}}, rows)
+	assert.Equal(t, 2, len(profile.ResultProcessors))
+
+	assert.Equal(t, []string{
+		"FT.PROFILE idx SEARCH QUERY hello LIMIT 0 1",
+		"FT.PROFILE idx AGGREGATE QUERY hello LOAD 1 @title",
+	}, conn.commands)
+
+	_, _, _, err = c.ProfileAggregate(NewAggregateQuery().SetCursor(NewCursor()))
+	assert.NotNil(t, err)
+}
@@ -1,8 +1,12 @@
 package redisearch
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/gomodule/redigo/redis"
 )
@@ -74,6 +78,63 @@ type SummaryOptions struct {
 	Separator    string // default "..."
 }
 
+// GeoUnit is the distance unit of a geo radius
+type GeoUnit string
+
+// Geo radius units
+const (
+	GeoUnitMeters     GeoUnit = "m"
+	GeoUnitKilometers GeoUnit = "km"
+	GeoUnitMiles      GeoUnit = "mi"
+	GeoUnitFeet       GeoUnit = "ft"
+)
+
+// GeoFilter limits the results to documents whose geo field is within the given radius
+// from a longitude/latitude point.
+// See http://redisearch.io/Commands/#ftsearch
+type GeoFilter struct {
+	Field  string
+	Lon    float64
+	Lat    float64
+	Radius float64
+	Unit   GeoUnit
+}
+
+func (g GeoFilter) validate() error {
+	switch g.Unit {
+	case GeoUnitMeters, GeoUnitKilometers, GeoUnitMiles, GeoUnitFeet:
+	default:
+		return fmt.Errorf("geo filter on %s: unsupported unit %q", g.Field, g.Unit)
+	}
+	if g.Lon < -180 || g.Lon > 180 || g.Lat < -85.05112878 || g.Lat > 85.05112878 {
+		return fmt.Errorf("geo filter on %s: invalid coordinates %v,%v", g.Field, g.Lon, g.Lat)
+	}
+	if g.Radius < 0 {
+		return fmt.Errorf("geo filter on %s: negative radius %v", g.Field, g.Radius)
+	}
+	return nil
+}
+
+// serialize returns the GEOFILTER clause, as used by FT.SEARCH
+func (g GeoFilter) serialize() (redis.Args, error) {
+	if err := g.validate(); err != nil {
+		return nil, err
+	}
+	return redis.Args{"GEOFILTER", g.Field, g.Lon, g.Lat, g.Radius, string(g.Unit)}, nil
+}
+
+// queryExpr returns the geo filter as a query expression, e.g. `@location:[-122.41 37.77 10 km]`
+func (g GeoFilter) queryExpr() (string, error) {
+	if err := g.validate(); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("@%s:[%s %s %s %s]", g.Field,
+		strconv.FormatFloat(g.Lon, 'f', -1, 64),
+		strconv.FormatFloat(g.Lat, 'f', -1, 64),
+		strconv.FormatFloat(g.Radius, 'f', -1, 64),
+		g.Unit), nil
+}
+
 // Query is a single search query and all its parameters and predicates
 type Query struct {
 	Raw string
@@ -83,6 +144,10 @@ type Query struct {
 	Slop   int
 
 	Filters       []Predicate
+	GeoFilter     *GeoFilter
+	KNN           *KNNClause
+	Params        map[string]interface{}
+	Dialect       int
 	InKeys        []string
 	ReturnFields  []string
 	Language      string
@@ -128,9 +193,13 @@ func NewQuery(raw string) *Query {
 	}
 }
 
-func (q Query) serialize() redis.Args {
+func (q Query) serialize() (redis.Args, error) {
 
-	args := redis.Args{q.Raw}.AddFlat(q.Paging.serialize())
+	raw := q.Raw
+	if q.KNN != nil {
+		raw = q.KNN.queryExpr(raw)
+	}
+	args := redis.Args{raw}.AddFlat(q.Paging.serialize())
 	if q.Flags&QueryVerbatim != 0 {
 		args = args.Add("VERBATIM")
 	}
@@ -149,14 +218,34 @@ func (q Query) serialize() redis.Args {
 		args = args.Add("WITHSCORES")
 	}
 
+	for _, p := range q.Filters {
+		filter, err := p.serialize()
+		if err != nil {
+			return nil, err
+		}
+		args = args.AddFlat(filter)
+	}
+
+	if q.GeoFilter != nil {
+		filter, err := q.GeoFilter.serialize()
+		if err != nil {
+			return nil, err
+		}
+		args = args.AddFlat(filter)
+	}
+
 	if q.InKeys != nil {
 		args = args.Add("INKEYS", len(q.InKeys))
 		args = args.AddFlat(q.InKeys)
 	}
 
 	if q.ReturnFields != nil {
-		args = args.Add("RETURN", len(q.ReturnFields))
-		args = args.AddFlat(q.ReturnFields)
+		returnFields := q.ReturnFields
+		if q.KNN != nil {
+			returnFields = append(returnFields[:len(returnFields):len(returnFields)], q.KNN.scoreField())
+		}
+		args = args.Add("RETURN", len(returnFields))
+		args = args.AddFlat(returnFields)
 	}
 
 	if q.Scorer != "" {
@@ -200,14 +289,135 @@ func (q Query) serialize() redis.Args {
 			args = args.Add("SEPARATOR", q.SummarizeOpts.Separator)
 		}
 	}
-	return args
+
+	params, err := q.serializeParams()
+	if err != nil {
+		return nil, err
+	}
+	return append(args, params...), nil
 }
 
-// // AddPredicate adds a predicate to the query's filters
-// func (q *Query) AddPredicate(p Predicate) *Query {
-// 	q.Predicates = append(q.Predicates, p)
-// 	return q
-// }
+// serializeParams serializes the PARAMS clause, including the vector of the KNN clause, and the DIALECT.
+// Parameters and KNN clauses require at least the dialect 2
+func (q Query) serializeParams() (redis.Args, error) {
+	names := make([]string, 0, len(q.Params))
+	for name := range q.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	n := len(names)
+	if q.KNN != nil {
+		n++
+	}
+	args := redis.Args{}
+	if n > 0 {
+		args = args.Add("PARAMS", 2*n)
+		for _, name := range names {
+			value, err := formatParamValue(q.Params[name])
+			if err != nil {
+				return nil, fmt.Errorf("query parameter %s: %s", name, err)
+			}
+			args = append(args, name, value)
+		}
+		if q.KNN != nil {
+			args = append(args, knnVectorParam, q.KNN.Vector)
+		}
+	}
+	dialect := q.Dialect
+	if n > 0 && dialect < 2 {
+		dialect = 2
+	}
+	if dialect > 0 {
+		args = args.Add("DIALECT", dialect)
+	}
+	return args, nil
+}
+
+// formatParamValue formats a query parameter value, sent as it is when it is a string or a binary blob
+func formatParamValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string, []byte:
+		return v, nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	}
+	return nil, fmt.Errorf("unsupported value type %T", value)
+}
+
+// spellCheckArgs serializes the query for FT.SPELLCHECK, which accepts a DIALECT but no PARAMS clause
+func (q Query) spellCheckArgs() (redis.Args, error) {
+	q.Params = nil
+	return q.serialize()
+}
+
+// aggregateArgs serializes the query for FT.AGGREGATE, which has no FILTER or GEOFILTER clauses,
+// by merging the filters into the query string as range expressions
+func (q Query) aggregateArgs() (redis.Args, error) {
+	if len(q.Filters) == 0 && q.GeoFilter == nil {
+		return q.serialize()
+	}
+	exprs := make([]string, 0, len(q.Filters)+2)
+	if raw := strings.TrimSpace(q.Raw); raw != "" && raw != "*" {
+		exprs = append(exprs, "("+raw+")")
+	}
+	for _, p := range q.Filters {
+		expr, err := p.queryExpr()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+	if q.GeoFilter != nil {
+		expr, err := q.GeoFilter.queryExpr()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+	q.Raw = strings.Join(exprs, " ")
+	q.Filters = nil
+	q.GeoFilter = nil
+	return q.serialize()
+}
+
+// AddFilter adds a numeric predicate to the query's filters
+func (q *Query) AddFilter(p Predicate) *Query {
+	q.Filters = append(q.Filters, p)
+	return q
+}
+
+// SetGeoFilter limits the results to documents whose geo field is within radius of the given point
+func (q *Query) SetGeoFilter(field string, lon, lat, radius float64, unit GeoUnit) *Query {
+	q.GeoFilter = &GeoFilter{
+		Field:  field,
+		Lon:    lon,
+		Lat:    lat,
+		Radius: radius,
+		Unit:   unit,
+	}
+	return q
+}
+
+// SetParam binds a value to a parameter of the query, referenced as $name in the query string.
+// Values can be strings, numbers or binary blobs, e.g. encoded vectors.
+// Parameters are sent apart from the query string, so they need no escaping
+func (q *Query) SetParam(name string, value interface{}) *Query {
+	if q.Params == nil {
+		q.Params = make(map[string]interface{})
+	}
+	q.Params[name] = value
+	return q
+}
+
+// SetDialect sets the dialect of the query syntax
+func (q *Query) SetDialect(dialect int) *Query {
+	q.Dialect = dialect
+	return q
+}
 
 // Limit sets the paging offset and limit for the query
 // you can use LIMIT 0 0 to count the number of documents in the resultset without actually returning them
@@ -301,6 +511,15 @@ func (q *Query) SummarizeOptions(opts SummaryOptions) *Query {
 }
 
 func SerializeSchema(s *Schema, args redis.Args) (redis.Args, error) {
+	if s.Definition != nil {
+		args = s.Definition.Serialize(args)
+	}
+	if s.Options.MaxTextFieldsFlag {
+		args = append(args, "MAXTEXTFIELDS")
+	}
+	if s.Options.Temporary > 0 {
+		args = append(args, "TEMPORARY", s.Options.Temporary)
+	}
 	if s.Options.NoFieldFlags {
 		args = append(args, "NOFIELDS")
 	}
@@ -310,87 +529,139 @@ func SerializeSchema(s *Schema, args redis.Args) (redis.Args, error) {
 	if s.Options.NoOffsetVectors {
 		args = append(args, "NOOFFSETS")
 	}
+	if s.Options.NoHighlights {
+		args = append(args, "NOHL")
+	}
 	if s.Options.Stopwords != nil {
 		args = args.Add("STOPWORDS", len(s.Options.Stopwords))
 		if len(s.Options.Stopwords) > 0 {
 			args = args.AddFlat(s.Options.Stopwords)
 		}
 	}
+	if s.Options.SkipInitialScan {
+		args = append(args, "SKIPINITIALSCAN")
+	}
 
 	args = append(args, "SCHEMA")
 	for _, f := range s.Fields {
+		var err error
+		if args, err = serializeField(f, args); err != nil {
+			return nil, err
+		}
+	}
+	return args, nil
+}
+
+// serializeField appends the definition of a field to args, as expected by FT.CREATE and FT.ALTER
+func serializeField(f Field, args redis.Args) (redis.Args, error) {
+	switch f.Type {
+	case TextField:
+
+		args = append(serializeFieldName(f, args), "TEXT")
+		if f.Options != nil {
+			opts, ok := f.Options.(TextFieldOptions)
+			if !ok {
+				return nil, errors.New("Invalid text field options type")
+			}
+
+			if opts.Weight != 0 && opts.Weight != 1 {
+				args = append(args, "WEIGHT", opts.Weight)
+			}
+			if opts.NoStem {
+				args = append(args, "NOSTEM")
+			}
+
+			if opts.Sortable {
+				args = append(args, "SORTABLE")
+			}
+
+			if opts.NoIndex {
+				args = append(args, "NOINDEX")
+			}
+		}
 
-		switch f.Type {
-		case TextField:
-
-			args = append(args, f.Name, "TEXT")
-			if f.Options != nil {
-				opts, ok := f.Options.(TextFieldOptions)
-				if !ok {
-					return nil, errors.New("Invalid text field options type")
-				}
-
-				if opts.Weight != 0 && opts.Weight != 1 {
-					args = append(args, "WEIGHT", opts.Weight)
-				}
-				if opts.NoStem {
-					args = append(args, "NOSTEM")
-				}
-
-				if opts.Sortable {
-					args = append(args, "SORTABLE")
-				}
-
-				if opts.NoIndex {
-					args = append(args, "NOINDEX")
-				}
+	case NumericField:
+		args = append(serializeFieldName(f, args), "NUMERIC")
+		if f.Options != nil {
+			opts, ok := f.Options.(NumericFieldOptions)
+			if !ok {
+				return nil, errors.New("Invalid numeric field options type")
 			}
 
-		case NumericField:
-			args = append(args, f.Name, "NUMERIC")
-			if f.Options != nil {
-				opts, ok := f.Options.(NumericFieldOptions)
-				if !ok {
-					return nil, errors.New("Invalid numeric field options type")
-				}
-
-				if opts.Sortable {
-					args = append(args, "SORTABLE")
-				}
-				if opts.NoIndex {
-					args = append(args, "NOINDEX")
-				}
+			if opts.Sortable {
+				args = append(args, "SORTABLE")
+			}
+			if opts.NoIndex {
+				args = append(args, "NOINDEX")
+			}
+		}
+	case GeoField:
+		args = append(serializeFieldName(f, args), "GEO")
+		if f.Options != nil {
+			opts, ok := f.Options.(GeoFieldOptions)
+			if !ok {
+				return nil, errors.New("Invalid geo field options type")
 			}
-		case TagField:
-			args = append(args, f.Name, "TAG")
-			if f.Options != nil {
-				opts, ok := f.Options.(TagFieldOptions)
-				if !ok {
-					return nil, errors.New("Invalid tag field options type")
-				}
-				if opts.Separator != 0 {
-					args = append(args, "SEPARATOR", fmt.Sprintf("%c", opts.Separator))
-
-				}
-				if opts.Sortable {
-					args = append(args, "SORTABLE")
-				}
-				if opts.NoIndex {
-					args = append(args, "NOINDEX")
-				}
+			if opts.NoIndex {
+				args = append(args, "NOINDEX")
 			}
-		default:
-			return nil, fmt.Errorf("Unsupported field type %v", f.Type)
 		}
+	case TagField:
+		args = append(serializeFieldName(f, args), "TAG")
+		if f.Options != nil {
+			opts, ok := f.Options.(TagFieldOptions)
+			if !ok {
+				return nil, errors.New("Invalid tag field options type")
+			}
+			if opts.Separator != 0 {
+				args = append(args, "SEPARATOR", fmt.Sprintf("%c", opts.Separator))
 
+			}
+			if opts.Sortable {
+				args = append(args, "SORTABLE")
+			}
+			if opts.NoIndex {
+				args = append(args, "NOINDEX")
+			}
+		}
+	case VectorField:
+		opts, ok := f.Options.(VectorFieldOptions)
+		if !ok {
+			return nil, errors.New("Invalid vector field options type")
+		}
+		attrs, err := opts.serialize()
+		if err != nil {
+			return nil, err
+		}
+		args = append(serializeFieldName(f, args), "VECTOR", string(opts.Algorithm), len(attrs))
+		args = append(args, attrs...)
+	default:
+		return nil, fmt.Errorf("Unsupported field type %v", f.Type)
 	}
 	return args, nil
 }
 
+// serializeFieldName appends the name of the field to args, followed by its alias when set
+func serializeFieldName(f Field, args redis.Args) redis.Args {
+	args = append(args, f.Name)
+	if f.As != "" {
+		args = append(args, "AS", f.As)
+	}
+	return args
+}
+
 // IndexOptions indexes multiple documents on the index, with optional Options passed to options
 func (i *Client) IndexOptions(opts IndexingOptions, docs ...Document) error {
+	return i.IndexOptionsContext(context.Background(), opts, docs...)
+}
 
-	conn := i.pool.Get()
+// IndexOptionsContext is the context-aware version of IndexOptions
+func (i *Client) IndexOptionsContext(ctx context.Context, opts IndexingOptions, docs ...Document) error {
+
+	conn, err := i.getConn(ctx)
+	if err != nil {
+		return err
+	}
 	defer conn.Close()
 
 	n := 0
@@ -427,7 +698,10 @@ func (i *Client) IndexOptions(opts IndexingOptions, docs ...Document) error {
 	}
 
 	for n > 0 {
-		if _, err := conn.Receive(); err != nil {
+		if _, err := receiveContext(ctx, conn); err != nil {
+			if err == ctx.Err() {
+				return err
+			}
 			if merr == nil {
 				merr = NewMultiError(len(docs))
 			}
@@ -484,4 +758,35 @@ type IndexInfo struct {
 	BytesPerRecordAvg    float64 `redis:"bytes_per_record_avg"`
 	OffsetsPerTermAvg    float64 `redis:"offsets_per_term_avg"`
 	OffsetBitsPerTermAvg float64 `redis:"offset_bits_per_record_avg"`
+
+	// Indexing is 1 while RediSearch 2.x scans the keys followed by the index, 0 once done
+	Indexing uint64 `redis:"indexing"`
+
+	// PercentIndexed is the fraction of the followed keys scanned so far, from 0 to 1
+	PercentIndexed float64 `redis:"percent_indexed"`
+
+	// HashIndexingFailures is the number of followed keys that could not be indexed, i.e. with invalid numeric values
+	HashIndexingFailures uint64 `redis:"hash_indexing_failures"`
+
+	GCStats     GCStats
+	CursorStats CursorStats
+}
+
+// GCStats are the statistics of the garbage collector of an index
+type GCStats struct {
+	BytesCollected     uint64  `redis:"bytes_collected"`
+	TotalMsRun         uint64  `redis:"total_ms_run"`
+	TotalCycles        uint64  `redis:"total_cycles"`
+	AverageCycleTimeMs float64 `redis:"average_cycle_time_ms"`
+	LastRunTimeMs      uint64  `redis:"last_run_time_ms"`
+	NumericTreesMissed uint64  `redis:"gc_numeric_trees_missed"`
+	BlocksDenied       uint64  `redis:"gc_blocks_denied"`
+}
+
+// CursorStats are the statistics of the aggregation cursors, of the index and of all the indexes
+type CursorStats struct {
+	GlobalIdle    uint64 `redis:"global_idle"`
+	GlobalTotal   uint64 `redis:"global_total"`
+	IndexCapacity uint64 `redis:"index_capacity"`
+	IndexTotal    uint64 `redis:"index_total"`
 }
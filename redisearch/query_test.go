@@ -67,6 +67,11 @@ func TestQuery_serialize(t *testing.T) {
 	type fields struct {
 		Raw           string
 		Flags         Flag
+		Filters       []Predicate
+		GeoFilter     *GeoFilter
+		KNN           *KNNClause
+		Params        map[string]interface{}
+		Dialect       int
 		InKeys        []string
 		ReturnFields  []string
 		Language      string
@@ -88,6 +93,27 @@ func TestQuery_serialize(t *testing.T) {
 		{"QueryInOrder", fields{Raw: raw, Flags: QueryInOrder}, redis.Args{raw, "LIMIT", 0, 0, "INORDER"}},
 		{"QueryWithPayloads", fields{Raw: raw, Flags: QueryWithPayloads}, redis.Args{raw, "LIMIT", 0, 0, "WITHPAYLOADS"}},
 		{"QueryWithScores", fields{Raw: raw, Flags: QueryWithScores}, redis.Args{raw, "LIMIT", 0, 0, "WITHSCORES"}},
+		{"Filters", fields{Raw: raw, Filters: []Predicate{InRange("price", 10, 20.5, true), GreaterThan("stock", 0)}},
+			redis.Args{raw, "LIMIT", 0, 0, "FILTER", "price", "10", "20.5", "FILTER", "stock", "(0", "+inf"}},
+		{"GeoFilter", fields{Raw: raw, GeoFilter: &GeoFilter{"location", -122.41, 37.77, 10, GeoUnitKilometers}},
+			redis.Args{raw, "LIMIT", 0, 0, "GEOFILTER", "location", -122.41, 37.77, 10.0, "km"}},
+		{"KNN", fields{Raw: raw, KNN: NewKNNClause(10, "vec", []byte{1, 2})},
+			redis.Args{"(test_query)=>[KNN 10 @vec $KNN_VECTOR AS __vec_score]", "LIMIT", 0, 0,
+				"PARAMS", 2, "KNN_VECTOR", []byte{1, 2}, "DIALECT", 2}},
+		{"KNN-all", fields{Raw: "*", KNN: NewKNNClause(5, "vec", []byte{1}).SetEFRuntime(20).SetScoreField("dist"),
+			ReturnFields: []string{"title"}},
+			redis.Args{"*=>[KNN 5 @vec $KNN_VECTOR EF_RUNTIME 20 AS dist]", "LIMIT", 0, 0, "RETURN", 2, "title", "dist",
+				"PARAMS", 2, "KNN_VECTOR", []byte{1}, "DIALECT", 2}},
+		{"Params", fields{Raw: "@title:$title @price:[$min +inf]", Params: map[string]interface{}{
+			"title": "hello world", "min": 10.5, "count": 3, "blob": []byte{0, 1}}},
+			redis.Args{"@title:$title @price:[$min +inf]", "LIMIT", 0, 0,
+				"PARAMS", 8, "blob", []byte{0, 1}, "count", "3", "min", "10.5", "title", "hello world", "DIALECT", 2}},
+		{"Params-dialect", fields{Raw: raw, Params: map[string]interface{}{"p": "v"}, Dialect: 3},
+			redis.Args{raw, "LIMIT", 0, 0, "PARAMS", 2, "p", "v", "DIALECT", 3}},
+		{"Dialect", fields{Raw: raw, Dialect: 1}, redis.Args{raw, "LIMIT", 0, 0, "DIALECT", 1}},
+		{"KNN-params", fields{Raw: "@title:$title", KNN: NewKNNClause(3, "vec", []byte{1}), Params: map[string]interface{}{"title": "a"}},
+			redis.Args{"(@title:$title)=>[KNN 3 @vec $KNN_VECTOR AS __vec_score]", "LIMIT", 0, 0,
+				"PARAMS", 4, "title", "a", "KNN_VECTOR", []byte{1}, "DIALECT", 2}},
 		{"InKeys", fields{Raw: raw, InKeys: []string{"test_key"}}, redis.Args{raw, "LIMIT", 0, 0, "INKEYS", 1, "test_key"}},
 		{"ReturnFields", fields{Raw: raw, ReturnFields: []string{"test_field"}}, redis.Args{raw, "LIMIT", 0, 0, "RETURN", 1, "test_field"}},
 		{"Language", fields{Raw: raw, Language: "chinese"}, redis.Args{raw, "LIMIT", 0, 0, "LANGUAGE", "chinese"}},
@@ -112,6 +138,11 @@ func TestQuery_serialize(t *testing.T) {
 			q := Query{
 				Raw:           tt.fields.Raw,
 				Flags:         tt.fields.Flags,
+				Filters:       tt.fields.Filters,
+				GeoFilter:     tt.fields.GeoFilter,
+				KNN:           tt.fields.KNN,
+				Params:        tt.fields.Params,
+				Dialect:       tt.fields.Dialect,
 				InKeys:        tt.fields.InKeys,
 				ReturnFields:  tt.fields.ReturnFields,
 				Language:      tt.fields.Language,
@@ -121,9 +152,151 @@ func TestQuery_serialize(t *testing.T) {
 				HighlightOpts: tt.fields.HighlightOpts,
 				SummarizeOpts: tt.fields.SummarizeOpts,
 			}
-			if g := q.serialize(); !reflect.DeepEqual(g, tt.want) {
-				t.Errorf("serialize() = %v, want %v", g, tt.want)
+			if g, err := q.serialize(); err != nil || !reflect.DeepEqual(g, tt.want) {
+				t.Errorf("serialize() = %v, %v, want %v", g, err, tt.want)
 			}
 		})
 	}
 }
+
+func TestQuery_serializeParamError(t *testing.T) {
+	q := NewQuery("$p").SetParam("p", []string{"a"})
+	if _, err := q.serialize(); err == nil {
+		t.Errorf("serialize() expected an error for an unsupported parameter type")
+	}
+}
+
+func TestQuery_serializeFilterError(t *testing.T) {
+	q := NewQuery("test_query").AddFilter(Equals("price", "ten"))
+	if _, err := q.serialize(); err == nil {
+		t.Errorf("serialize() expected error for non numeric filter value")
+	}
+}
+
+func TestQuery_spellCheckArgs(t *testing.T) {
+	tests := []struct {
+		name  string
+		query *Query
+		want  redis.Args
+	}{
+		{"params", NewQuery("@title:$title").SetParam("title", "hello"), redis.Args{"@title:$title"}},
+		{"params-dialect", NewQuery("@title:$title").SetParam("title", "hello").SetDialect(3),
+			redis.Args{"@title:$title", "DIALECT", 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := tt.query.spellCheckArgs(); err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("spellCheckArgs() = %v, %v, want %v", got, err, tt.want)
+			}
+			if len(tt.query.Params) == 0 {
+				t.Errorf("spellCheckArgs() modified the query parameters")
+			}
+		})
+	}
+}
+
+func TestQuery_aggregateArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   *Query
+		want    redis.Args
+		wantErr bool
+	}{
+		{"no filters", NewQuery("foo"), redis.Args{"foo"}, false},
+		{"wildcard", NewQuery("*").AddFilter(InRange("price", 10, 20, false)), redis.Args{"@price:[(10 (20]"}, false},
+		{"with query", NewQuery("foo | bar").AddFilter(LessThanEquals("price", 20)).AddFilter(GreaterThanEquals("year", 2000)),
+			redis.Args{"(foo | bar) @price:[-inf 20] @year:[2000 +inf]"}, false},
+		{"geo", NewQuery("foo").SetGeoFilter("location", -122.41, 37.77, 10, GeoUnitMiles).AddFilter(Equals("price", 10)),
+			redis.Args{"(foo) @price:[10 10] @location:[-122.41 37.77 10 mi]"}, false},
+		{"params", NewQuery("@title:$title").SetParam("title", "hello").AddFilter(Equals("price", 10)),
+			redis.Args{"(@title:$title) @price:[10 10]", "PARAMS", 2, "title", "hello", "DIALECT", 2}, false},
+		{"knn", NewQuery("*").SetKNN(NewKNNClause(3, "vec", []byte{1})).AddFilter(Equals("price", 10)),
+			redis.Args{"(@price:[10 10])=>[KNN 3 @vec $KNN_VECTOR AS __vec_score]", "PARAMS", 2, "KNN_VECTOR", []byte{1}, "DIALECT", 2}, false},
+		{"invalid", NewQuery("*").AddFilter(InRange("price", 10, nil, true)), nil, true},
+		{"invalid geo unit", NewQuery("*").SetGeoFilter("location", -122.41, 37.77, 10, GeoUnit("yd")), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.query.aggregateArgs()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("aggregateArgs() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("aggregateArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSerializeSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  *Schema
+		want    redis.Args
+		wantErr bool
+	}{
+		{"geo", NewSchema(DefaultOptions).AddField(NewGeoField("location")),
+			redis.Args{"idx", "SCHEMA", "location", "GEO"}, false},
+		{"geo-noindex", NewSchema(DefaultOptions).AddField(NewGeoFieldOptions("location", GeoFieldOptions{NoIndex: true})),
+			redis.Args{"idx", "SCHEMA", "location", "GEO", "NOINDEX"}, false},
+		{"geo-invalid-options", NewSchema(DefaultOptions).AddField(Field{Name: "location", Type: GeoField, Options: TagFieldOptions{}}),
+			nil, true},
+		{"definition", NewSchema(DefaultOptions).AddField(NewTextField("title")).
+			SetIndexDefinition(NewIndexDefinition().AddPrefix("product:").AddPrefix("item:").
+				SetFilterExpression("@price>0").SetLanguage("english").SetLanguageField("lang").
+				SetScore(0.5).SetScoreField("rank").SetPayloadField("payload")),
+			redis.Args{"idx", "ON", "HASH", "PREFIX", 2, "product:", "item:", "FILTER", "@price>0",
+				"LANGUAGE", "english", "LANGUAGE_FIELD", "lang", "SCORE", 0.5, "SCORE_FIELD", "rank",
+				"PAYLOAD_FIELD", "payload", "SCHEMA", "title", "TEXT"}, false},
+		{"definition-json", NewSchema(DefaultOptions).AddField(NewTextField("title")).
+			SetIndexDefinition(NewIndexDefinition().SetIndexOn(JSONIndex)),
+			redis.Args{"idx", "ON", "JSON", "SCHEMA", "title", "TEXT"}, false},
+		{"json-aliases", NewSchema(DefaultOptions).
+			AddField(NewTextFieldOptions("$.title", TextFieldOptions{Sortable: true}).SetAs("title")).
+			AddField(NewTagField("$.tags[*]").SetAs("tags")).
+			AddField(NewNumericField("$.price")).
+			SetIndexDefinition(NewIndexDefinition().SetIndexOn(JSONIndex).AddPrefix("product:")),
+			redis.Args{"idx", "ON", "JSON", "PREFIX", 1, "product:", "SCHEMA",
+				"$.title", "AS", "title", "TEXT", "SORTABLE", "$.tags[*]", "AS", "tags", "TAG", "SEPARATOR", ",",
+				"$.price", "NUMERIC"}, false},
+		{"vector-flat", NewSchema(DefaultOptions).AddField(NewVectorFieldOptions("vec", VectorFieldOptions{
+			Algorithm: VectorFlat, Type: VectorFloat32, Dim: 128, DistanceMetric: VectorCosine, InitialCap: 1000, BlockSize: 100})),
+			redis.Args{"idx", "SCHEMA", "vec", "VECTOR", "FLAT", 10, "TYPE", "FLOAT32", "DIM", 128, "DISTANCE_METRIC", "COSINE",
+				"INITIAL_CAP", 1000, "BLOCK_SIZE", 100}, false},
+		{"vector-hnsw", NewSchema(DefaultOptions).AddField(NewVectorFieldOptions("vec", VectorFieldOptions{
+			Algorithm: VectorHNSW, Type: VectorFloat64, Dim: 4, DistanceMetric: VectorL2, M: 16, EFConstruction: 200,
+			EFRuntime: 10, Epsilon: 0.01})),
+			redis.Args{"idx", "SCHEMA", "vec", "VECTOR", "HNSW", 14, "TYPE", "FLOAT64", "DIM", 4, "DISTANCE_METRIC", "L2",
+				"M", 16, "EF_CONSTRUCTION", 200, "EF_RUNTIME", 10, "EPSILON", 0.01}, false},
+		{"vector-missing-dim", NewSchema(DefaultOptions).AddField(NewVectorFieldOptions("vec", VectorFieldOptions{
+			Algorithm: VectorFlat, Type: VectorFloat32, DistanceMetric: VectorL2})), nil, true},
+		{"vector-hnsw-option-on-flat", NewSchema(DefaultOptions).AddField(NewVectorFieldOptions("vec", VectorFieldOptions{
+			Algorithm: VectorFlat, Type: VectorFloat32, Dim: 4, DistanceMetric: VectorL2, M: 16})), nil, true},
+		{"vector-no-options", NewSchema(DefaultOptions).AddField(Field{Name: "vec", Type: VectorField}), nil, true},
+		{"index-options", NewSchema(Options{MaxTextFieldsFlag: true, Temporary: 60, NoOffsetVectors: true,
+			NoHighlights: true, Stopwords: []string{}, SkipInitialScan: true}).AddField(NewTextField("title")),
+			redis.Args{"idx", "MAXTEXTFIELDS", "TEMPORARY", 60, "NOOFFSETS", "NOHL", "STOPWORDS", 0,
+				"SKIPINITIALSCAN", "SCHEMA", "title", "TEXT"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SerializeSchema(tt.schema, redis.Args{"idx"})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SerializeSchema() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SerializeSchema() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSchema_options(t *testing.T) {
+	sc := NewSchema(Options{NoFrequencies: true, Stopwords: []string{"a", "the"}}).AddField(NewTextField("title"))
+	want := redis.Args{"idx", "NOFREQS", "STOPWORDS", 2, "a", "the", "SCHEMA", "title", "TEXT"}
+	if got, err := SerializeSchema(sc, redis.Args{"idx"}); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("SerializeSchema() = %v, %v, want %v", got, err, want)
+	}
+}
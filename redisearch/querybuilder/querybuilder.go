@@ -0,0 +1,375 @@
+// Package querybuilder provides a typed AST for RediSearch query expressions.
+//
+// Nodes are composed with the constructor functions of this package and rendered to
+// the RediSearch query syntax with String(), taking care of escaping and precedence.
+// The rendered string is meant to be used as the Raw text of a redisearch.Query:
+//
+//	q := querybuilder.NewQuery(querybuilder.Intersect(
+//		querybuilder.Field("title", querybuilder.Union(querybuilder.Term("hello"), querybuilder.Prefix("wor"))),
+//		querybuilder.Tags("tags", "sci-fi", "space opera"),
+//		querybuilder.Not(querybuilder.Range("price", math.Inf(-1), 10)),
+//	))
+//	// q.Raw == `(@title:(hello|wor*) @tags:{sci\-fi | space\ opera} -@price:[-inf 10])`
+package querybuilder
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/RediSearch/redisearch-go/redisearch"
+)
+
+// Node is a single node of a query expression
+type Node interface {
+	// String renders the node in RediSearch query syntax
+	String() string
+}
+
+// NewQuery creates a new redisearch.Query with the rendered expression as its raw query string
+func NewQuery(n Node) *redisearch.Query {
+	return redisearch.NewQuery(n.String())
+}
+
+// escape escapes a single term, so that it is parsed as one literal token.
+// On top of the separators escaped by redisearch.EscapeTextFileString,
+// backslashes, the union operator and whitespaces are escaped as well
+func escape(value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = redisearch.EscapeTextFileString(value)
+	value = strings.Replace(value, "|", `\|`, -1)
+	value = strings.Replace(value, " ", `\ `, -1)
+	return value
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// grouped renders the node wrapped in parentheses, unless it already is
+func grouped(n Node) string {
+	switch n := n.(type) {
+	case *UnionNode:
+		if len(n.children()) > 1 {
+			return n.String()
+		}
+	case *IntersectNode:
+		if len(n.children()) > 1 {
+			return n.String()
+		}
+	}
+	return "(" + n.String() + ")"
+}
+
+// TermNode is a single term, matched after stemming and expansion
+type TermNode struct {
+	Value string
+}
+
+// Term creates a node matching a single term
+func Term(value string) *TermNode {
+	return &TermNode{Value: value}
+}
+
+func (t *TermNode) String() string {
+	return escape(t.Value)
+}
+
+// PhraseNode is an exact phrase, matching all the terms in order
+type PhraseNode struct {
+	Terms []string
+}
+
+// Phrase creates a node matching the terms as an exact phrase, i.e. "hello world"
+func Phrase(terms ...string) *PhraseNode {
+	return &PhraseNode{Terms: terms}
+}
+
+func (p *PhraseNode) String() string {
+	terms := make([]string, len(p.Terms))
+	for i, t := range p.Terms {
+		terms[i] = escape(t)
+	}
+	return `"` + strings.Join(terms, " ") + `"`
+}
+
+// PrefixNode matches all the terms starting with Prefix
+type PrefixNode struct {
+	Prefix string
+}
+
+// Prefix creates a node matching all the terms starting with the given prefix, i.e. hel*
+func Prefix(prefix string) *PrefixNode {
+	return &PrefixNode{Prefix: prefix}
+}
+
+func (p *PrefixNode) String() string {
+	return escape(p.Prefix) + "*"
+}
+
+// FuzzyNode matches all the terms within a Levenshtein distance from Term
+type FuzzyNode struct {
+	Term     string
+	Distance int
+}
+
+// Fuzzy creates a node matching the terms within the given Levenshtein distance from term, i.e. %term%.
+// The distance is clamped to the [1,3] range supported by RediSearch
+func Fuzzy(term string, distance int) *FuzzyNode {
+	if distance < 1 {
+		distance = 1
+	} else if distance > 3 {
+		distance = 3
+	}
+	return &FuzzyNode{Term: term, Distance: distance}
+}
+
+func (f *FuzzyNode) String() string {
+	marks := strings.Repeat("%", f.Distance)
+	return marks + escape(f.Term) + marks
+}
+
+// OptionalNode is an expression that does not filter the results, but ranks higher the ones matching it
+type OptionalNode struct {
+	Node Node
+}
+
+// Optional creates an optional node, i.e. ~term
+func Optional(n Node) *OptionalNode {
+	return &OptionalNode{Node: n}
+}
+
+func (o *OptionalNode) String() string {
+	return "~" + o.Node.String()
+}
+
+// NotNode excludes the results matching Node
+type NotNode struct {
+	Node Node
+}
+
+// Not creates a negated node, i.e. -term
+func Not(n Node) *NotNode {
+	return &NotNode{Node: n}
+}
+
+func (n *NotNode) String() string {
+	return "-" + n.Node.String()
+}
+
+// UnionNode matches the results matching any of its nodes
+type UnionNode struct {
+	Nodes []Node
+}
+
+// Union creates a node matching any of the given nodes, i.e. (a|b)
+func Union(nodes ...Node) *UnionNode {
+	return &UnionNode{Nodes: nodes}
+}
+
+func (u *UnionNode) children() []string {
+	return renderAll(u.Nodes)
+}
+
+func (u *UnionNode) String() string {
+	return join(u.children(), "|")
+}
+
+// IntersectNode matches the results matching all of its nodes
+type IntersectNode struct {
+	Nodes []Node
+}
+
+// Intersect creates a node matching all of the given nodes, i.e. (a b)
+func Intersect(nodes ...Node) *IntersectNode {
+	return &IntersectNode{Nodes: nodes}
+}
+
+func (i *IntersectNode) children() []string {
+	return renderAll(i.Nodes)
+}
+
+func (i *IntersectNode) String() string {
+	return join(i.children(), " ")
+}
+
+// renderAll renders the nodes, skipping the empty ones
+func renderAll(nodes []Node) []string {
+	ret := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		if n == nil {
+			continue
+		}
+		if s := n.String(); s != "" {
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
+
+func join(rendered []string, sep string) string {
+	switch len(rendered) {
+	case 0:
+		return ""
+	case 1:
+		return rendered[0]
+	}
+	return "(" + strings.Join(rendered, sep) + ")"
+}
+
+// FieldNode limits Node to the given text fields
+type FieldNode struct {
+	Fields []string
+	Node   Node
+}
+
+// Field creates a node limiting the expression to a single field, i.e. @title:hello
+func Field(field string, n Node) *FieldNode {
+	return Fields([]string{field}, n)
+}
+
+// Fields creates a node limiting the expression to any of the given fields, i.e. @title|body:hello
+func Fields(fields []string, n Node) *FieldNode {
+	return &FieldNode{Fields: fields, Node: n}
+}
+
+func (f *FieldNode) String() string {
+	var expr string
+	switch n := f.Node.(type) {
+	case *TermNode, *PhraseNode, *PrefixNode, *FuzzyNode:
+		expr = n.String()
+	default:
+		expr = grouped(n)
+	}
+	return "@" + strings.Join(f.Fields, "|") + ":" + expr
+}
+
+// RangeNode matches the documents whose numeric field is between Min and Max.
+// Infinite bounds are rendered as -inf/+inf
+type RangeNode struct {
+	Field        string
+	Min          float64
+	Max          float64
+	ExclusiveMin bool
+	ExclusiveMax bool
+}
+
+// Range creates a node matching an inclusive numeric range, i.e. @price:[10 20].
+// Use math.Inf for an unbounded side
+func Range(field string, min, max float64) *RangeNode {
+	return &RangeNode{Field: field, Min: min, Max: max}
+}
+
+// ExcludeMin makes the lower bound of the range exclusive
+func (r *RangeNode) ExcludeMin() *RangeNode {
+	r.ExclusiveMin = true
+	return r
+}
+
+// ExcludeMax makes the upper bound of the range exclusive
+func (r *RangeNode) ExcludeMax() *RangeNode {
+	r.ExclusiveMax = true
+	return r
+}
+
+func formatBound(f float64, exclusive bool) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case exclusive:
+		return "(" + formatFloat(f)
+	}
+	return formatFloat(f)
+}
+
+func (r *RangeNode) String() string {
+	return fmt.Sprintf("@%s:[%s %s]", r.Field, formatBound(r.Min, r.ExclusiveMin), formatBound(r.Max, r.ExclusiveMax))
+}
+
+// TagNode matches the documents having any of the values in their tag field
+type TagNode struct {
+	Field  string
+	Values []string
+}
+
+// Tags creates a node matching any of the given tag values, i.e. @tags:{a | b}
+func Tags(field string, values ...string) *TagNode {
+	return &TagNode{Field: field, Values: values}
+}
+
+func (t *TagNode) String() string {
+	values := make([]string, len(t.Values))
+	for i, v := range t.Values {
+		values[i] = escape(v)
+	}
+	return "@" + t.Field + ":{" + strings.Join(values, " | ") + "}"
+}
+
+// GeoNode matches the documents whose geo field is within Radius from the Lon/Lat point
+type GeoNode struct {
+	Field  string
+	Lon    float64
+	Lat    float64
+	Radius float64
+	Unit   redisearch.GeoUnit
+}
+
+// Geo creates a node matching a geo radius, i.e. @location:[-122.41 37.77 10 km]
+func Geo(field string, lon, lat, radius float64, unit redisearch.GeoUnit) *GeoNode {
+	return &GeoNode{Field: field, Lon: lon, Lat: lat, Radius: radius, Unit: unit}
+}
+
+func (g *GeoNode) String() string {
+	return fmt.Sprintf("@%s:[%s %s %s %s]", g.Field, formatFloat(g.Lon), formatFloat(g.Lat), formatFloat(g.Radius), g.Unit)
+}
+
+// Attribute is a query attribute, changing how its expression is scored or matched
+type Attribute struct {
+	Name  string
+	Value string
+}
+
+// Weight sets the weight of the expression in the ranking of the results
+func Weight(weight float64) Attribute {
+	return Attribute{Name: "weight", Value: formatFloat(weight)}
+}
+
+// Slop sets the number of terms allowed between the terms of the expression
+func Slop(slop int) Attribute {
+	return Attribute{Name: "slop", Value: strconv.Itoa(slop)}
+}
+
+// InOrder requires the terms of the expression to appear in the same order as in the query
+func InOrder(inOrder bool) Attribute {
+	return Attribute{Name: "inorder", Value: strconv.FormatBool(inOrder)}
+}
+
+// Phonetic enables or disables phonetic matching of the expression
+func Phonetic(phonetic bool) Attribute {
+	return Attribute{Name: "phonetic", Value: strconv.FormatBool(phonetic)}
+}
+
+// AttributeNode applies query attributes to Node
+type AttributeNode struct {
+	Node       Node
+	Attributes []Attribute
+}
+
+// WithAttributes applies the given attributes to a node, i.e. (hello world) => { $weight: 2; }
+func WithAttributes(n Node, attributes ...Attribute) *AttributeNode {
+	return &AttributeNode{Node: n, Attributes: attributes}
+}
+
+func (a *AttributeNode) String() string {
+	if len(a.Attributes) == 0 {
+		return a.Node.String()
+	}
+	s := grouped(a.Node) + " => {"
+	for _, attr := range a.Attributes {
+		s += " $" + attr.Name + ": " + attr.Value + ";"
+	}
+	return s + " }"
+}
@@ -0,0 +1,69 @@
+package querybuilder
+
+import (
+	"math"
+	"testing"
+
+	"github.com/RediSearch/redisearch-go/redisearch"
+)
+
+func TestNode_String(t *testing.T) {
+	tests := []struct {
+		name string
+		node Node
+		want string
+	}{
+		{"term", Term("hello"), `hello`},
+		{"term-escaped", Term("foo-bar.baz"), `foo\-bar\.baz`},
+		{"term-union-operator", Term("a|b"), `a\|b`},
+		{"term-whitespace", Term("hello world"), `hello\ world`},
+		{"term-backslash", Term(`a\b`), `a\\b`},
+		{"phrase", Phrase("hello", "world"), `"hello world"`},
+		{"phrase-escaped", Phrase(`say "hi"`), `"say\ \"hi\""`},
+		{"prefix", Prefix("hel"), `hel*`},
+		{"fuzzy", Fuzzy("hello", 1), `%hello%`},
+		{"fuzzy-2", Fuzzy("hello", 2), `%%hello%%`},
+		{"fuzzy-clamped", Fuzzy("hello", 10), `%%%hello%%%`},
+		{"optional", Optional(Term("hello")), `~hello`},
+		{"not", Not(Term("hello")), `-hello`},
+		{"not-intersect", Not(Intersect(Term("a"), Term("b"))), `-(a b)`},
+		{"union", Union(Term("a"), Term("b")), `(a|b)`},
+		{"union-single", Union(Term("a")), `a`},
+		{"union-empty", Union(), ``},
+		{"intersect", Intersect(Term("a"), Union(Term("b"), Term("c"))), `(a (b|c))`},
+		{"intersect-skip-empty", Intersect(Term("a"), nil, Union()), `a`},
+		{"field-term", Field("title", Term("hello")), `@title:hello`},
+		{"field-phrase", Field("title", Phrase("hello", "world")), `@title:"hello world"`},
+		{"field-union", Field("title", Union(Term("a"), Term("b"))), `@title:(a|b)`},
+		{"field-not", Field("title", Not(Term("a"))), `@title:(-a)`},
+		{"fields", Fields([]string{"title", "body"}, Term("hello")), `@title|body:hello`},
+		{"range", Range("price", 10, 20), `@price:[10 20]`},
+		{"range-exclusive", Range("price", 10, 20.5).ExcludeMin().ExcludeMax(), `@price:[(10 (20.5]`},
+		{"range-inf", Range("price", math.Inf(-1), math.Inf(1)).ExcludeMin(), `@price:[-inf +inf]`},
+		{"tags", Tags("tags", "foo bar", "baz"), `@tags:{foo\ bar | baz}`},
+		{"geo", Geo("location", -122.41, 37.77, 10, redisearch.GeoUnitKilometers), `@location:[-122.41 37.77 10 km]`},
+		{"attributes", WithAttributes(Intersect(Term("a"), Term("b")), Weight(2), Slop(1), InOrder(true)),
+			`(a b) => { $weight: 2; $slop: 1; $inorder: true; }`},
+		{"attributes-term", WithAttributes(Term("a"), Phonetic(false)), `(a) => { $phonetic: false; }`},
+		{"attributes-empty", WithAttributes(Term("a")), `a`},
+		{"nested", Intersect(
+			Field("title", Union(Term("hello"), Prefix("wor"))),
+			Tags("tags", "sci-fi", "space opera"),
+			Not(Range("price", math.Inf(-1), 10)),
+		), `(@title:(hello|wor*) @tags:{sci\-fi | space\ opera} -@price:[-inf 10])`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.node.String(); got != tt.want {
+				t.Errorf("String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewQuery(t *testing.T) {
+	q := NewQuery(Union(Term("a"), Term("b")))
+	if q.Raw != "(a|b)" {
+		t.Errorf("NewQuery() raw = %v, want %v", q.Raw, "(a|b)")
+	}
+}
@@ -303,6 +303,78 @@ func TestTags(t *testing.T) {
 
 }
 
+func TestGeo(t *testing.T) {
+	c := createClient("testung")
+
+	sc := redisearch.NewSchema(redisearch.DefaultOptions).
+		AddField(redisearch.NewTextField("name")).
+		AddField(redisearch.NewSortableNumericField("price")).
+		AddField(redisearch.NewGeoField("location"))
+	c.Drop()
+	assert.Nil(t, c.CreateIndex(sc))
+
+	docs := []redisearch.Document{
+		redisearch.NewDocument("store1", 1).Set("name", "downtown store").Set("price", 10).SetGeo("location", -122.4194, 37.7749),
+		redisearch.NewDocument("store2", 1).Set("name", "oakland store").Set("price", 20).SetGeo("location", -122.2711, 37.8044),
+		redisearch.NewDocument("store3", 1).Set("name", "los angeles store").Set("price", 30).SetGeo("location", -118.2437, 34.0522),
+	}
+	assert.Nil(t, c.Index(docs...))
+
+	_, total, err := c.Search(redisearch.NewQuery("store").SetGeoFilter("location", -122.4194, 37.7749, 50, redisearch.GeoUnitKilometers))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, total)
+
+	docs, total, err = c.Search(redisearch.NewQuery("store").
+		SetGeoFilter("location", -122.4194, 37.7749, 50, redisearch.GeoUnitKilometers).
+		AddFilter(redisearch.GreaterThan("price", 10)))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "store2", docs[0].Id)
+
+	_, _, err = c.Search(redisearch.NewQuery("store").SetGeoFilter("location", -122.4194, 37.7749, 50, redisearch.GeoUnit("yd")))
+	assert.NotNil(t, err)
+}
+
+type testMovie struct {
+	Title    string    `redisearch:"title,sortable,weight=5"`
+	Year     int       `redisearch:"year,sortable"`
+	Genres   []string  `redisearch:"genres"`
+	Released time.Time `redisearch:"released"`
+}
+
+func TestStructMapping(t *testing.T) {
+	c := createClient("testung")
+
+	sc, err := redisearch.NewSchemaFromStruct(testMovie{}, redisearch.DefaultOptions)
+	assert.Nil(t, err)
+	c.Drop()
+	assert.Nil(t, c.CreateIndex(sc))
+
+	movie := testMovie{
+		Title:    "The Matrix",
+		Year:     1999,
+		Genres:   []string{"action", "sci-fi"},
+		Released: time.Unix(922838400, 0),
+	}
+	doc, err := redisearch.NewDocumentFromStruct("movie1", 1, &movie)
+	assert.Nil(t, err)
+	assert.Nil(t, c.Index(doc))
+
+	docs, total, err := c.Search(redisearch.NewQuery("@genres:{sci\\-fi}"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+
+	var got testMovie
+	assert.Nil(t, docs[0].Decode(&got))
+	assert.Equal(t, movie, got)
+
+	doc2, err := c.Get("movie1")
+	assert.Nil(t, err)
+	got = testMovie{}
+	assert.Nil(t, doc2.Decode(&got))
+	assert.Equal(t, movie, got)
+}
+
 func TestDelete(t *testing.T) {
 	c := createClient("testung")
 
@@ -423,3 +495,29 @@ func ExampleClient() {
 }
 
 
+
+func TestParams(t *testing.T) {
+	c := createClient("testparams")
+	sc := redisearch.NewSchema(redisearch.DefaultOptions).
+		AddField(redisearch.NewTextField("title")).
+		AddField(redisearch.NewNumericField("price"))
+	c.Drop()
+	assert.Nil(t, c.CreateIndex(sc))
+	defer c.Drop()
+
+	assert.Nil(t, c.Index(redisearch.NewDocument("doc1", 1).Set("title", "hello world").Set("price", 10),
+		redisearch.NewDocument("doc2", 1).Set("title", "hello world").Set("price", 20)))
+
+	// the parameter value is not parsed as query syntax
+	q := redisearch.NewQuery("@title:$title @price:[$min +inf]").
+		SetParam("title", "hello").
+		SetParam("min", 15)
+	docs, total, err := c.Search(q)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "doc2", docs[0].Id)
+
+	_, count, err := c.Aggregate(redisearch.NewAggregateQuery().SetQuery(q))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+}
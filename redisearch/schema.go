@@ -1,5 +1,10 @@
 package redisearch
 
+import (
+	"fmt"
+	"strconv"
+)
+
 // FieldType is an enumeration of field/property types
 type FieldType int
 
@@ -30,6 +35,22 @@ type Options struct {
 	// If the list is nil the default stop-words list is used.
 	// See https://oss.redislabs.com/redisearch/Stopwords.html#default_stop-word_list
 	Stopwords []string
+
+	// If set, the index is encoded so that more than 32 text fields can be added to it, i.e. with FT.ALTER.
+	// This is an option that is applied and index level.
+	MaxTextFieldsFlag bool
+
+	// If set, the index expires after being idle for this number of seconds.
+	// This is an option that is applied and index level.
+	Temporary int
+
+	// If set, we avoid saving the term offsets used for highlighting.
+	// This is an option that is applied and index level.
+	NoHighlights bool
+
+	// If set, the keys already existing when the index is created are not scanned and indexed.
+	// This is an option that is applied and index level.
+	SkipInitialScan bool
 }
 
 // DefaultOptions represents the default options
@@ -53,14 +74,63 @@ const (
 
 	// TagField is a field used for compact indexing of comma separated values
 	TagField
+
+	// VectorField is a field of vectors, searched by similarity with KNN queries
+	VectorField
 )
 
+// String returns the name of the field type, as in FT.CREATE
+func (t FieldType) String() string {
+	switch t {
+	case TextField:
+		return "TEXT"
+	case NumericField:
+		return "NUMERIC"
+	case GeoField:
+		return "GEO"
+	case TagField:
+		return "TAG"
+	case VectorField:
+		return "VECTOR"
+	}
+	return "FieldType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// FieldTypeError is returned when a command requires a field of a given type, and the
+// field is missing from the schema of the index or has a different type
+type FieldTypeError struct {
+	Field    string
+	Expected FieldType
+
+	// Missing is true if the field is not in the schema, Actual is its type otherwise
+	Missing bool
+	Actual  FieldType
+}
+
+func (e *FieldTypeError) Error() string {
+	if e.Missing {
+		return fmt.Sprintf("field %s is not in the schema of the index, a %v field is required", e.Field, e.Expected)
+	}
+	return fmt.Sprintf("field %s is a %v field, a %v field is required", e.Field, e.Actual, e.Expected)
+}
+
 // Field represents a single field's Schema
 type Field struct {
 	Name     string
 	Type     FieldType
 	Sortable bool
 	Options  interface{}
+
+	// As is the name the field is referenced with in queries and results.
+	// It is required when Name is a JSONPath, for indexes of JSON documents
+	As string
+}
+
+// SetAs sets the name the field is referenced with in queries and results, i.e.
+// NewTextField("$.title").SetAs("title")
+func (f Field) SetAs(alias string) Field {
+	f.As = alias
+	return f
 }
 
 // TextFieldOptions Options for text fields - weight and stemming enabled/disabled.
@@ -85,6 +155,74 @@ type NumericFieldOptions struct {
 	NoIndex  bool
 }
 
+// GeoFieldOptions Options for geo fields
+type GeoFieldOptions struct {
+	NoIndex bool
+}
+
+// VectorAlgorithm is the indexing algorithm of a vector field
+type VectorAlgorithm string
+
+const (
+	// VectorFlat is the brute force algorithm, exact but slower on large data sets
+	VectorFlat VectorAlgorithm = "FLAT"
+
+	// VectorHNSW is the Hierarchical Navigable Small World graph algorithm, approximate but faster
+	VectorHNSW VectorAlgorithm = "HNSW"
+)
+
+// VectorType is the type of the elements of a vector
+type VectorType string
+
+const (
+	// VectorFloat32 vectors are encoded with EncodeFloat32Vector
+	VectorFloat32 VectorType = "FLOAT32"
+
+	// VectorFloat64 vectors are encoded with EncodeFloat64Vector
+	VectorFloat64 VectorType = "FLOAT64"
+)
+
+// VectorDistanceMetric is the distance metric of the vectors of a vector field
+type VectorDistanceMetric string
+
+const (
+	// VectorL2 is the euclidean distance
+	VectorL2 VectorDistanceMetric = "L2"
+
+	// VectorIP is the inner product
+	VectorIP VectorDistanceMetric = "IP"
+
+	// VectorCosine is the cosine distance
+	VectorCosine VectorDistanceMetric = "COSINE"
+)
+
+// VectorFieldOptions Options for vector fields. Algorithm, Type, Dim and DistanceMetric are required,
+// the other options are left to the server defaults when zero
+type VectorFieldOptions struct {
+	Algorithm      VectorAlgorithm
+	Type           VectorType
+	Dim            int
+	DistanceMetric VectorDistanceMetric
+
+	// InitialCap is the initial capacity of the index, in vectors
+	InitialCap int
+
+	// BlockSize is the size of the blocks of vectors allocated by the FLAT algorithm
+	BlockSize int
+
+	// M is the maximum number of outgoing edges of the nodes of the HNSW graph
+	M int
+
+	// EFConstruction is the number of candidates considered while building the HNSW graph
+	EFConstruction int
+
+	// EFRuntime is the default number of candidates considered by HNSW KNN queries
+	EFRuntime int
+
+	// Epsilon is the relative factor of the radius of HNSW range queries
+	Epsilon float64
+}
+
 // NewTextField creates a new text field with the given weight
 func NewTextField(name string) Field {
 	return Field{
@@ -152,17 +290,46 @@ func NewSortableNumericField(name string) Field {
 	return f
 }
 
+// NewGeoField creates a new geo field with the given name
+func NewGeoField(name string) Field {
+	return Field{
+		Name: name,
+		Type: GeoField,
+	}
+}
+
+// NewGeoFieldOptions creates a new geo field with the given options
+func NewGeoFieldOptions(name string, options GeoFieldOptions) Field {
+	f := NewGeoField(name)
+	f.Options = options
+	return f
+}
+
+// NewVectorFieldOptions creates a new vector field with the given options
+func NewVectorFieldOptions(name string, options VectorFieldOptions) Field {
+	return Field{
+		Name:    name,
+		Type:    VectorField,
+		Options: options,
+	}
+}
+
 // Schema represents an index schema Schema, or how the index would
 // treat documents sent to it.
 type Schema struct {
 	Fields  []Field
 	Options Options
+
+	// Definition defines the keys followed by the index in RediSearch 2.x.
+	// When nil, the index is created in the 1.x form, and documents are added with FT.ADD
+	Definition *IndexDefinition
 }
 
 // NewSchema creates a new Schema object
 func NewSchema(opts Options) *Schema {
 	return &Schema{
-		Fields: []Field{},
+		Fields:  []Field{},
+		Options: opts,
 	}
 }
 
@@ -174,3 +341,9 @@ func (m *Schema) AddField(f Field) *Schema {
 	m.Fields = append(m.Fields, f)
 	return m
 }
+
+// SetIndexDefinition sets the definition of the keys followed by the index
+func (m *Schema) SetIndexDefinition(d *IndexDefinition) *Schema {
+	m.Definition = d
+	return m
+}
@@ -0,0 +1,105 @@
+package redisearch
+
+import "reflect"
+
+// SchemaDiff is the difference between a desired schema and the schema of an existing index
+type SchemaDiff struct {
+	// Added are the fields missing from the index, which can be added with Client.AlterAddFields
+	Added []Field
+
+	// Changed are the desired fields whose type or options differ from the ones in the index
+	Changed []Field
+
+	// Removed are the fields of the index missing from the desired schema
+	Removed []Field
+}
+
+// RequiresRebuild returns true if the index must be created again to match the desired schema,
+// as FT.ALTER can only add fields
+func (d SchemaDiff) RequiresRebuild() bool {
+	return len(d.Changed) > 0 || len(d.Removed) > 0
+}
+
+// Diff compares the desired schema with the current schema of an index, usually the one returned by Client.Info, i.e.
+//
+//	info, err := c.Info()
+//	...
+//	diff := desired.Diff(&info.Schema)
+//	if !diff.RequiresRebuild() && len(diff.Added) > 0 {
+//		err = c.AlterAddFields(diff.Added...)
+//	}
+//
+// Fields are matched by alias, or by name when they have none. The options of vector fields are
+// compared only when they are known for the current schema
+func (m *Schema) Diff(current *Schema) SchemaDiff {
+	var diff SchemaDiff
+	existing := make(map[string]Field, len(current.Fields))
+	for _, f := range current.Fields {
+		existing[f.key()] = f
+	}
+	for _, f := range m.Fields {
+		cf, ok := existing[f.key()]
+		if !ok {
+			diff.Added = append(diff.Added, f)
+			continue
+		}
+		delete(existing, f.key())
+		if !f.equivalent(cf) {
+			diff.Changed = append(diff.Changed, f)
+		}
+	}
+	// keep the order of the current schema
+	for _, f := range current.Fields {
+		if _, ok := existing[f.key()]; ok {
+			diff.Removed = append(diff.Removed, f)
+		}
+	}
+	return diff
+}
+
+// key returns the name the field is referenced with
+func (f Field) key() string {
+	if f.As != "" {
+		return f.As
+	}
+	return f.Name
+}
+
+// equivalent returns true if the fields are indexed the same way
+func (f Field) equivalent(other Field) bool {
+	if f.Name != other.Name || f.Type != other.Type {
+		return false
+	}
+	if f.Type == VectorField && (f.Options == nil || other.Options == nil) {
+		return true
+	}
+	return reflect.DeepEqual(f.normalizedOptions(), other.normalizedOptions())
+}
+
+// normalizedOptions returns the options of the field with the defaults applied
+func (f Field) normalizedOptions() interface{} {
+	switch opts := f.Options.(type) {
+	case nil:
+		switch f.Type {
+		case TextField:
+			return TextFieldOptions{Weight: 1}
+		case NumericField:
+			return NumericFieldOptions{}
+		case GeoField:
+			return GeoFieldOptions{}
+		case TagField:
+			return TagFieldOptions{Separator: ','}
+		}
+	case TextFieldOptions:
+		if opts.Weight == 0 {
+			opts.Weight = 1
+		}
+		return opts
+	case TagFieldOptions:
+		if opts.Separator == 0 {
+			opts.Separator = ','
+		}
+		return opts
+	}
+	return f.Options
+}
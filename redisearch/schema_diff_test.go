@@ -0,0 +1,77 @@
+package redisearch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSchema_Diff(t *testing.T) {
+	current := NewSchema(DefaultOptions).
+		AddField(NewTextField("title")).
+		AddField(NewSortableNumericField("price")).
+		AddField(NewTagField("category")).
+		AddField(NewTextField("$.body").SetAs("body"))
+	tests := []struct {
+		name        string
+		desired     *Schema
+		want        SchemaDiff
+		wantRebuild bool
+	}{
+		{"same", NewSchema(DefaultOptions).
+			AddField(NewTextFieldOptions("title", TextFieldOptions{Weight: 1})).
+			AddField(NewNumericFieldOptions("price", NumericFieldOptions{Sortable: true})).
+			AddField(NewTagFieldOptions("category", TagFieldOptions{})).
+			AddField(NewTextField("$.body").SetAs("body")),
+			SchemaDiff{}, false},
+		{"added", NewSchema(DefaultOptions).
+			AddField(NewTextField("title")).
+			AddField(NewSortableNumericField("price")).
+			AddField(NewTagField("category")).
+			AddField(NewTextField("$.body").SetAs("body")).
+			AddField(NewGeoField("location")),
+			SchemaDiff{Added: []Field{NewGeoField("location")}}, false},
+		{"changed", NewSchema(DefaultOptions).
+			AddField(NewTagField("title")).
+			AddField(NewNumericField("price")).
+			AddField(NewTagFieldOptions("category", TagFieldOptions{Separator: ';'})).
+			AddField(NewTextField("$.content").SetAs("body")),
+			SchemaDiff{Changed: []Field{
+				NewTagField("title"),
+				NewNumericField("price"),
+				NewTagFieldOptions("category", TagFieldOptions{Separator: ';'}),
+				NewTextField("$.content").SetAs("body"),
+			}}, true},
+		{"removed", NewSchema(DefaultOptions).
+			AddField(NewSortableNumericField("price")).
+			AddField(NewTextField("$.body").SetAs("body")),
+			SchemaDiff{Removed: []Field{NewTextField("title"), NewTagField("category")}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.desired.Diff(current)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %v, want %v", got, tt.want)
+			}
+			if got.RequiresRebuild() != tt.wantRebuild {
+				t.Errorf("RequiresRebuild() = %v, want %v", got.RequiresRebuild(), tt.wantRebuild)
+			}
+		})
+	}
+}
+
+func TestSchema_DiffVector(t *testing.T) {
+	opts := VectorFieldOptions{Algorithm: VectorFlat, Type: VectorFloat32, Dim: 4, DistanceMetric: VectorL2}
+	desired := NewSchema(DefaultOptions).AddField(NewVectorFieldOptions("vec", opts))
+
+	// the options of the vector fields returned by Info are not known
+	current := NewSchema(DefaultOptions).AddField(Field{Name: "vec", Type: VectorField})
+	if diff := desired.Diff(current); !reflect.DeepEqual(diff, SchemaDiff{}) {
+		t.Errorf("Diff() = %v, want no difference", diff)
+	}
+
+	opts.Dim = 8
+	current = NewSchema(DefaultOptions).AddField(NewVectorFieldOptions("vec", opts))
+	if diff := desired.Diff(current); !diff.RequiresRebuild() {
+		t.Errorf("Diff() = %v, want a changed field", diff)
+	}
+}
@@ -0,0 +1,494 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	// replicasRefresh is how often a SentinelPool reading from the replicas fetches them again from the sentinels
+	replicasRefresh = 30 * time.Second
+	// replicasRetry is how often it fetches them again when none of them is healthy
+	replicasRetry = time.Second
+)
+
+// SentinelPool is a ConnPool for Redis deployments monitored by Sentinel. The address of the primary
+// is resolved from the sentinels on first use, and again after a failover, detected by READONLY errors
+// or by broken connections to the primary. Write commands failing with READONLY are retried once on the new primary.
+// Read-only commands such as FT.SEARCH, FT.AGGREGATE and FT.GET can be sent to the replicas with SetReadFromReplicas
+type SentinelPool struct {
+	sync.Mutex
+	sentinels        []string
+	masterName       string
+	readFromReplicas bool
+
+	// primary is the address of the primary, empty until resolved
+	primary  string
+	replicas []string
+	pools    map[string]ConnPool
+
+	// replicasAt is the time the replicas were fetched at, zero if they must be fetched on next read
+	replicasAt time.Time
+
+	// newPool creates the pool of the connections to a sentinel or a Redis node, with the lock held
+	newPool func(addr string) ConnPool
+
+	// now returns the current time
+	now func() time.Time
+}
+
+// NewSentinelPool creates a pool for the primary named masterName, monitored by the sentinels given as host:port.
+// The connections to the nodes are configured with opts, i.e. WithAuth or WithTLSConfig. The connections to the
+// sentinels share their TLS, timeout and pool settings, but not their AUTH credentials and database:
+// they are authenticated with WithSentinelAuth
+func NewSentinelPool(sentinels []string, masterName string, opts ...ClientOption) *SentinelPool {
+	p := &SentinelPool{
+		sentinels:  sentinels,
+		masterName: masterName,
+		pools:      map[string]ConnPool{},
+		now:        time.Now,
+	}
+	o := newClientOptions(opts)
+	so := o.sentinelOptions()
+	// newPool is called by nodePool with the lock held
+	p.newPool = func(addr string) ConnPool {
+		if sliceIndex(p.sentinels, addr) != -1 {
+			return &SingleHostPool{so.newPool(addr)}
+		}
+		return &SingleHostPool{o.newPool(addr)}
+	}
+	return p
+}
+
+// SetReadFromReplicas sets whether the read-only commands are sent to the replicas. A connection
+// sends all its reads to the same replica, so that the cursors of FT.AGGREGATE can be read from it.
+// Reads fall back to the primary if there are no healthy replicas.
+// The replicas are fetched again from the sentinels every 30 seconds, or every second while none is healthy
+func (p *SentinelPool) SetReadFromReplicas(readFromReplicas bool) *SentinelPool {
+	p.Lock()
+	defer p.Unlock()
+	p.readFromReplicas = readFromReplicas
+	p.replicasAt = time.Time{}
+	return p
+}
+
+// Get returns a connection to the current primary, or to a replica for the read-only commands
+func (p *SentinelPool) Get() redis.Conn {
+	return &sentinelConn{pool: p}
+}
+
+// Resolve asks the sentinels for the addresses of the primary, and of the replicas if reads are sent to them.
+// The primary must confirm its role, as a sentinel may not know about a failover yet
+func (p *SentinelPool) Resolve() error {
+	p.Lock()
+	sentinels := append([]string{}, p.sentinels...)
+	withReplicas := p.readFromReplicas
+	p.Unlock()
+
+	err := errors.New("SentinelPool: no sentinels")
+	for _, addr := range sentinels {
+		conn := p.nodePool(addr).Get()
+		primary, replicas, e := querySentinel(conn, p.masterName, withReplicas)
+		conn.Close()
+		if e == nil {
+			e = p.checkRole(primary)
+		}
+		if e != nil {
+			err = e
+			continue
+		}
+		p.Lock()
+		p.primary, p.replicas = primary, replicas
+		if withReplicas {
+			p.replicasAt = p.now()
+		}
+		p.Unlock()
+		return nil
+	}
+	return err
+}
+
+// refreshReplicas fetches the healthy replicas from the sentinels, keeping the known ones if none replies
+func (p *SentinelPool) refreshReplicas() {
+	p.Lock()
+	sentinels := append([]string{}, p.sentinels...)
+	p.Unlock()
+
+	for _, addr := range sentinels {
+		conn := p.nodePool(addr).Get()
+		replicas, err := queryReplicas(conn, p.masterName)
+		conn.Close()
+		if err != nil {
+			continue
+		}
+		p.Lock()
+		p.replicas = replicas
+		p.Unlock()
+		return
+	}
+}
+
+// Close closes the pools of the sentinels and of the nodes
+func (p *SentinelPool) Close() error {
+	p.Lock()
+	defer p.Unlock()
+	var err error
+	for addr, pool := range p.pools {
+		if closer, ok := pool.(interface {
+			Close() error
+		}); ok {
+			if e := closer.Close(); e != nil && err == nil {
+				err = e
+			}
+		}
+		delete(p.pools, addr)
+	}
+	return err
+}
+
+// nodePool returns the pool of a sentinel or a node, creating it on first use
+func (p *SentinelPool) nodePool(addr string) ConnPool {
+	p.Lock()
+	defer p.Unlock()
+	pool, found := p.pools[addr]
+	if !found {
+		pool = p.newPool(addr)
+		p.pools[addr] = pool
+	}
+	return pool
+}
+
+// checkRole checks that the node at addr is a primary with ROLE
+func (p *SentinelPool) checkRole(addr string) error {
+	conn := p.nodePool(addr).Get()
+	defer conn.Close()
+	role, err := redis.Values(conn.Do("ROLE"))
+	if err != nil {
+		return err
+	}
+	if len(role) == 0 {
+		return fmt.Errorf("SentinelPool: invalid ROLE reply from %s", addr)
+	}
+	if name, _ := redis.String(role[0], nil); name != "master" {
+		return fmt.Errorf("SentinelPool: %s is a %s, not the primary", addr, name)
+	}
+	return nil
+}
+
+// primaryAddr returns the address of the primary, resolving it if unknown
+func (p *SentinelPool) primaryAddr() (string, error) {
+	p.Lock()
+	primary := p.primary
+	p.Unlock()
+	if primary != "" {
+		return primary, nil
+	}
+	if err := p.Resolve(); err != nil {
+		return "", err
+	}
+	p.Lock()
+	defer p.Unlock()
+	return p.primary, nil
+}
+
+// replicaAddr returns the address of a random replica, or an empty string if the reads go to the primary.
+// The replicas are fetched again if they are stale
+func (p *SentinelPool) replicaAddr() string {
+	if _, err := p.primaryAddr(); err != nil {
+		return ""
+	}
+	p.Lock()
+	if !p.readFromReplicas {
+		p.Unlock()
+		return ""
+	}
+	refresh := replicasRefresh
+	if len(p.replicas) == 0 {
+		refresh = replicasRetry
+	}
+	if now := p.now(); now.Sub(p.replicasAt) >= refresh {
+		// the other connections keep the known replicas meanwhile
+		p.replicasAt = now
+		p.Unlock()
+		p.refreshReplicas()
+		p.Lock()
+	}
+	defer p.Unlock()
+	if len(p.replicas) == 0 {
+		return ""
+	}
+	return p.replicas[rand.Intn(len(p.replicas))]
+}
+
+// failover forgets the primary at addr, to resolve it again on next use
+func (p *SentinelPool) failover(addr string) {
+	p.Lock()
+	defer p.Unlock()
+	if p.primary == addr {
+		p.primary = ""
+	}
+}
+
+// replicaDown stops sending reads to the replica at addr, until the replicas are fetched again
+func (p *SentinelPool) replicaDown(addr string) {
+	p.Lock()
+	defer p.Unlock()
+	if ii := sliceIndex(p.replicas, addr); ii != -1 {
+		p.replicas = append(p.replicas[:ii:ii], p.replicas[ii+1:]...)
+	}
+}
+
+// querySentinel asks a sentinel for the address of the primary, and for the ones of its healthy replicas
+func querySentinel(conn redis.Conn, masterName string, withReplicas bool) (primary string, replicas []string, err error) {
+	hostPort, err := redis.Strings(conn.Do("SENTINEL", "get-master-addr-by-name", masterName))
+	if err == redis.ErrNil {
+		return "", nil, fmt.Errorf("SentinelPool: unknown master %s", masterName)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	if len(hostPort) != 2 {
+		return "", nil, fmt.Errorf("SentinelPool: invalid address of master %s: %v", masterName, hostPort)
+	}
+	primary = net.JoinHostPort(hostPort[0], hostPort[1])
+	if !withReplicas {
+		return primary, nil, nil
+	}
+	if replicas, err = queryReplicas(conn, masterName); err != nil {
+		return "", nil, err
+	}
+	return primary, replicas, nil
+}
+
+// queryReplicas asks a sentinel for the addresses of the healthy replicas of the primary
+func queryReplicas(conn redis.Conn, masterName string) (replicas []string, err error) {
+	entries, err := redis.Values(conn.Do("SENTINEL", "replicas", masterName))
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if addr := parseSentinelReplica(entry); addr != "" {
+			replicas = append(replicas, addr)
+		}
+	}
+	return replicas, nil
+}
+
+// parseSentinelReplica returns the address of a replica listed by SENTINEL replicas,
+// or an empty string if the sentinel flags it as down or disconnected
+func parseSentinelReplica(entry interface{}) string {
+	values, err := redis.Strings(entry, nil)
+	if err != nil {
+		return ""
+	}
+	var ip, port string
+	for ii := 0; ii+1 < len(values); ii += 2 {
+		switch values[ii] {
+		case "ip":
+			ip = values[ii+1]
+		case "port":
+			port = values[ii+1]
+		case "flags":
+			for _, flag := range strings.Split(values[ii+1], ",") {
+				if flag == "s_down" || flag == "o_down" || flag == "disconnected" {
+					return ""
+				}
+			}
+		}
+	}
+	if ip == "" || port == "" {
+		return ""
+	}
+	return net.JoinHostPort(ip, port)
+}
+
+// isReadOnlyCommand returns whether a command can be sent to a replica
+func isReadOnlyCommand(commandName string) bool {
+	switch strings.ToUpper(commandName) {
+	case "FT.SEARCH", "FT.AGGREGATE", "FT.CURSOR", "FT.GET", "FT.MGET", "FT.EXPLAIN", "FT.SPELLCHECK",
+		"FT.TAGVALS", "FT.SUGGET", "FT.PROFILE":
+		return true
+	}
+	return false
+}
+
+// isReadOnlyError returns whether a command failed because it was sent to a replica
+func isReadOnlyError(err error) bool {
+	rerr, ok := err.(redis.Error)
+	return ok && strings.HasPrefix(string(rerr), "READONLY")
+}
+
+// isConnError returns whether an error is a failure of the connection, rather than a reply of the server
+func isConnError(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, isRedisErr := err.(redis.Error)
+	return !isRedisErr
+}
+
+// sentinelConn is a connection of a SentinelPool. It holds a connection to the primary,
+// and one to a replica for the read-only commands, until it is closed
+type sentinelConn struct {
+	pool        *SentinelPool
+	primary     redis.Conn
+	primaryAddr string
+	replica     redis.Conn
+	replicaAddr string
+	closed      bool
+}
+
+// primaryConn returns the connection to the primary, resolving it on first use
+func (c *sentinelConn) primaryConn() (redis.Conn, error) {
+	if c.primary != nil {
+		return c.primary, nil
+	}
+	addr, err := c.pool.primaryAddr()
+	if err != nil {
+		return nil, err
+	}
+	c.primary, c.primaryAddr = c.pool.nodePool(addr).Get(), addr
+	return c.primary, nil
+}
+
+// resetPrimary forgets the primary after a failover, closing the connection to it.
+// If ctx is over, the command was interrupted rather than failed: the connection is closed, but the primary is kept
+func (c *sentinelConn) resetPrimary(ctx context.Context) {
+	if ctx.Err() == nil {
+		c.pool.failover(c.primaryAddr)
+	}
+	c.primary.Close()
+	c.primary, c.primaryAddr = nil, ""
+}
+
+// replicaConn returns the connection to the replica of the connection, nil if the reads go to the primary
+func (c *sentinelConn) replicaConn() redis.Conn {
+	if c.replica == nil {
+		if addr := c.pool.replicaAddr(); addr != "" {
+			c.replica, c.replicaAddr = c.pool.nodePool(addr).Get(), addr
+		}
+	}
+	return c.replica
+}
+
+func (c *sentinelConn) Do(commandName string, args ...interface{}) (interface{}, error) {
+	return c.DoContext(context.Background(), commandName, args...)
+}
+
+// DoContext runs a command, waiting for the reply of the node with its context-aware methods
+func (c *sentinelConn) DoContext(ctx context.Context, commandName string, args ...interface{}) (interface{}, error) {
+	if c.closed {
+		return nil, errors.New("redisearch: connection closed")
+	}
+	if isReadOnlyCommand(commandName) {
+		if replica := c.replicaConn(); replica != nil {
+			reply, err := doContext(ctx, replica, commandName, args...)
+			if !isConnError(err) {
+				return reply, err
+			}
+			// a read interrupted by ctx does not mean that the replica is down
+			interrupted := ctx.Err() != nil
+			if !interrupted {
+				c.pool.replicaDown(c.replicaAddr)
+			}
+			replica.Close()
+			c.replica, c.replicaAddr = nil, ""
+			if interrupted {
+				return reply, err
+			}
+			// reads can safely be sent again, to the primary
+		}
+	}
+	for retried := false; ; retried = true {
+		conn, err := c.primaryConn()
+		if err != nil {
+			return nil, err
+		}
+		reply, err := doContext(ctx, conn, commandName, args...)
+		if !isReadOnlyError(err) && !isConnError(err) {
+			return reply, err
+		}
+		c.resetPrimary(ctx)
+		// commands rejected with READONLY were not run, while broken connections may have run them
+		if retried || !isReadOnlyError(err) {
+			return reply, err
+		}
+	}
+}
+
+// Send pipelines a command to the primary
+func (c *sentinelConn) Send(commandName string, args ...interface{}) error {
+	if c.closed {
+		return errors.New("redisearch: connection closed")
+	}
+	conn, err := c.primaryConn()
+	if err != nil {
+		return err
+	}
+	return conn.Send(commandName, args...)
+}
+
+func (c *sentinelConn) Flush() error {
+	if c.primary == nil {
+		return nil
+	}
+	return c.primary.Flush()
+}
+
+// Receive returns the reply of a pipelined command. A failover is detected as for Do, but the commands are not retried
+func (c *sentinelConn) Receive() (interface{}, error) {
+	return c.ReceiveContext(context.Background())
+}
+
+// ReceiveContext is the context-aware version of Receive
+func (c *sentinelConn) ReceiveContext(ctx context.Context) (interface{}, error) {
+	if c.primary == nil {
+		return nil, errors.New("redisearch: no pending replies")
+	}
+	reply, err := receiveContext(ctx, c.primary)
+	if isReadOnlyError(err) || isConnError(err) {
+		c.resetPrimary(ctx)
+	}
+	return reply, err
+}
+
+// Err returns the error of the connection to the primary or to the replica, if broken
+func (c *sentinelConn) Err() error {
+	if c.closed {
+		return errors.New("redisearch: connection closed")
+	}
+	for _, conn := range []redis.Conn{c.primary, c.replica} {
+		if conn != nil {
+			if err := conn.Err(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Close returns the connections to their pools
+func (c *sentinelConn) Close() error {
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	var err error
+	for _, conn := range []redis.Conn{c.primary, c.replica} {
+		if conn != nil {
+			if e := conn.Close(); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	c.primary, c.replica = nil, nil
+	return err
+}
@@ -0,0 +1,255 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseSentinelReplica(t *testing.T) {
+	replica := func(flags string) []interface{} {
+		return []interface{}{[]byte("name"), []byte("10.0.0.2:6379"), []byte("ip"), []byte("10.0.0.2"),
+			[]byte("port"), []byte("6379"), []byte("flags"), []byte(flags)}
+	}
+	tests := []struct {
+		name  string
+		entry interface{}
+		want  string
+	}{
+		{"healthy", replica("slave"), "10.0.0.2:6379"},
+		{"subjectively-down", replica("slave,s_down"), ""},
+		{"objectively-down", replica("slave,o_down"), ""},
+		{"disconnected", replica("slave,disconnected"), ""},
+		{"no-address", []interface{}{[]byte("flags"), []byte("slave")}, ""},
+		{"invalid", int64(1), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseSentinelReplica(tt.entry); got != tt.want {
+				t.Errorf("parseSentinelReplica() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+var (
+	masterRole = []interface{}{[]byte("master"), int64(0), []interface{}{}}
+	slaveRole  = []interface{}{[]byte("slave"), []byte("10.0.0.1"), int64(6379), []byte("connected"), int64(0)}
+)
+
+// testSentinelPool returns a pool with a sentinel and the nodes 10.0.0.1:6379 and 10.0.0.2:6379
+func testSentinelPool(sentinel, node1, node2 *scriptedConn) *SentinelPool {
+	p := NewSentinelPool([]string{"10.0.0.9:26379"}, "mymaster")
+	p.newPool = func(addr string) ConnPool {
+		switch addr {
+		case "10.0.0.1:6379":
+			return &scriptedPool{conn: node1}
+		case "10.0.0.2:6379":
+			return &scriptedPool{conn: node2}
+		}
+		return &scriptedPool{conn: sentinel}
+	}
+	return p
+}
+
+func TestSentinelPool_failover(t *testing.T) {
+	sentinel := &scriptedConn{replies: []interface{}{
+		[]interface{}{[]byte("10.0.0.1"), []byte("6379")},
+		[]interface{}{[]byte("10.0.0.2"), []byte("6379")},
+	}}
+	node1 := &scriptedConn{replies: []interface{}{masterRole, int64(1), redis.Error("READONLY You can't write against a read only replica.")}}
+	node2 := &scriptedConn{replies: []interface{}{masterRole, int64(1)}}
+	p := testSentinelPool(sentinel, node1, node2)
+
+	conn := p.Get()
+	_, err := conn.Do("HSET", "doc1", "title", "hello")
+	assert.Nil(t, err)
+	// the write rejected by the former primary is retried on the new one
+	reply, err := conn.Do("HSET", "doc2", "title", "world")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), reply)
+	assert.Nil(t, conn.Close())
+
+	assert.Equal(t, []string{"SENTINEL get-master-addr-by-name mymaster", "SENTINEL get-master-addr-by-name mymaster"}, sentinel.commands)
+	assert.Equal(t, []string{"ROLE", "HSET doc1 title hello", "HSET doc2 title world"}, node1.commands)
+	assert.Equal(t, []string{"ROLE", "HSET doc2 title world"}, node2.commands)
+	assert.Equal(t, 2, node1.closed)
+	assert.Equal(t, "10.0.0.2:6379", p.primary)
+
+	// broken connections are not retried, but the primary is resolved again
+	node2.replies = nil
+	_, err = p.Get().Do("HSET", "doc3", "title", "again")
+	assert.NotNil(t, err)
+	assert.Equal(t, "", p.primary)
+}
+
+func TestSentinelPool_Receive(t *testing.T) {
+	sentinel := &scriptedConn{replies: []interface{}{
+		[]interface{}{[]byte("10.0.0.1"), []byte("6379")},
+		[]interface{}{[]byte("10.0.0.2"), []byte("6379")},
+	}}
+	node1 := &scriptedConn{replies: []interface{}{masterRole, redis.Error("READONLY You can't write against a read only replica.")}}
+	node2 := &scriptedConn{replies: []interface{}{masterRole, int64(1)}}
+	p := testSentinelPool(sentinel, node1, node2)
+
+	conn := p.Get()
+	assert.Nil(t, conn.Send("HSET", "doc1", "title", "hello"))
+	assert.Nil(t, conn.Flush())
+	_, err := conn.Receive()
+	assert.True(t, isReadOnlyError(err))
+	// closed after ROLE, and after the failover
+	assert.Equal(t, 2, node1.closed)
+
+	// the pipelined commands that follow are sent to the new primary
+	assert.Nil(t, conn.Send("HSET", "doc1", "title", "hello"))
+	assert.Nil(t, conn.Flush())
+	reply, err := conn.Receive()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), reply)
+	assert.Equal(t, []string{"ROLE", "HSET doc1 title hello"}, node1.commands)
+	assert.Equal(t, []string{"ROLE", "HSET doc1 title hello"}, node2.commands)
+}
+
+func TestSentinelPool_DoContext(t *testing.T) {
+	sentinel := &scriptedConn{replies: []interface{}{[]interface{}{[]byte("10.0.0.1"), []byte("6379")}}}
+	node1 := &contextConn{scriptedConn: &scriptedConn{replies: []interface{}{masterRole, int64(1), "OK"}}}
+	p := testSentinelPool(sentinel, node1.scriptedConn, &scriptedConn{})
+	p.newPool = func(addr string) ConnPool {
+		if addr == "10.0.0.1:6379" {
+			return &contextPool{conn: node1}
+		}
+		return &scriptedPool{conn: sentinel}
+	}
+
+	conn := p.Get()
+	reply, err := redis.DoContext(conn, context.Background(), "HSET", "doc1", "title", "hello")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), reply)
+	assert.Nil(t, conn.Send("HSET", "doc2", "title", "world"))
+	assert.Nil(t, conn.Flush())
+	reply, err = redis.ReceiveContext(conn, context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", reply)
+	assert.Equal(t, 2, node1.withContext)
+
+	// an interrupted command is not a failover
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = redis.DoContext(conn, ctx, "HSET", "doc3", "title", "again")
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, "10.0.0.1:6379", p.primary)
+	assert.Equal(t, 1, len(sentinel.commands))
+}
+
+func TestSentinelPool_replicas(t *testing.T) {
+	replicas := []interface{}{
+		[]interface{}{[]byte("ip"), []byte("10.0.0.2"), []byte("port"), []byte("6379"), []byte("flags"), []byte("slave")},
+		[]interface{}{[]byte("ip"), []byte("10.0.0.3"), []byte("port"), []byte("6379"), []byte("flags"), []byte("slave,s_down")},
+	}
+	sentinel := &scriptedConn{replies: []interface{}{[]interface{}{[]byte("10.0.0.1"), []byte("6379")}, replicas}}
+	node1 := &scriptedConn{replies: []interface{}{masterRole, int64(1), []interface{}{[]byte("title"), []byte("hello")}}}
+	node2 := &scriptedConn{replies: []interface{}{[]interface{}{int64(0)}}}
+	p := testSentinelPool(sentinel, node1, node2).SetReadFromReplicas(true)
+
+	conn := p.Get()
+	_, err := conn.Do("FT.SEARCH", "idx", "hello")
+	assert.Nil(t, err)
+	_, err = conn.Do("HSET", "doc1", "title", "hello")
+	assert.Nil(t, err)
+	// the replica is broken, the read falls back to the primary
+	reply, err := conn.Do("FT.GET", "idx", "doc1")
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{[]byte("title"), []byte("hello")}, reply)
+	assert.Nil(t, conn.Close())
+
+	assert.Equal(t, []string{"SENTINEL get-master-addr-by-name mymaster", "SENTINEL replicas mymaster"}, sentinel.commands)
+	assert.Equal(t, []string{"ROLE", "HSET doc1 title hello", "FT.GET idx doc1"}, node1.commands)
+	assert.Equal(t, []string{"FT.SEARCH idx hello", "FT.GET idx doc1"}, node2.commands)
+	assert.Equal(t, 0, len(p.replicas))
+}
+
+func TestSentinelPool_refreshReplicas(t *testing.T) {
+	replicas := []interface{}{
+		[]interface{}{[]byte("ip"), []byte("10.0.0.2"), []byte("port"), []byte("6379"), []byte("flags"), []byte("slave")},
+	}
+	search := []interface{}{int64(0)}
+	sentinel := &scriptedConn{replies: []interface{}{[]interface{}{[]byte("10.0.0.1"), []byte("6379")}, replicas, replicas}}
+	node1 := &scriptedConn{replies: []interface{}{masterRole, int64(1), search, search}}
+	node2 := &scriptedConn{replies: []interface{}{search, errors.New("broken"), search}}
+	p := testSentinelPool(sentinel, node1, node2)
+	now := time.Now()
+	p.now = func() time.Time { return now }
+
+	_, err := p.Get().Do("HSET", "doc1", "title", "hello")
+	assert.Nil(t, err)
+	// the replicas are fetched once reads are sent to them, after the resolution of the primary
+	p.SetReadFromReplicas(true)
+	for i := 0; i < 3; i++ {
+		_, err = p.Get().Do("FT.SEARCH", "idx", "hello")
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, 0, len(p.replicas))
+	// the broken replica is fetched again once the retry delay is over
+	now = now.Add(replicasRetry)
+	_, err = p.Get().Do("FT.SEARCH", "idx", "hello")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"10.0.0.2:6379"}, p.replicas)
+
+	assert.Equal(t, []string{"SENTINEL get-master-addr-by-name mymaster", "SENTINEL replicas mymaster",
+		"SENTINEL replicas mymaster"}, sentinel.commands)
+	assert.Equal(t, []string{"ROLE", "HSET doc1 title hello", "FT.SEARCH idx hello", "FT.SEARCH idx hello"}, node1.commands)
+	assert.Equal(t, []string{"FT.SEARCH idx hello", "FT.SEARCH idx hello", "FT.SEARCH idx hello"}, node2.commands)
+}
+
+func TestSentinelPool_Resolve(t *testing.T) {
+	sentinel := &scriptedConn{replies: []interface{}{nil, []interface{}{[]byte("10.0.0.1"), []byte("6379")}}}
+	node1 := &scriptedConn{replies: []interface{}{slaveRole}}
+	p := testSentinelPool(sentinel, node1, &scriptedConn{})
+	p.sentinels = []string{"10.0.0.9:26379", "10.0.0.10:26379"}
+
+	// the first sentinel does not know the master, the second one replies with a replica
+	err := p.Resolve()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "not the primary")
+	assert.Equal(t, "", p.primary)
+
+	_, err = p.Get().Do("HSET", "doc1", "title", "hello")
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"ROLE"}, node1.commands)
+}
+
+func TestNewSentinelPool_options(t *testing.T) {
+	p := NewSentinelPool([]string{"10.0.0.9:26379"}, "mymaster", WithAuth("", "secret"), WithDatabase(2),
+		WithMaxActive(10), WithIdleTimeout(time.Minute))
+	for _, addr := range []string{"10.0.0.9:26379", "10.0.0.1:6379"} {
+		pool, ok := p.nodePool(addr).(*SingleHostPool)
+		assert.True(t, ok)
+		assert.Equal(t, 10, pool.MaxActive)
+		assert.Equal(t, time.Minute, pool.IdleTimeout)
+	}
+	assert.Nil(t, p.Close())
+}
+
+func Test_clientOptions_sentinelOptions(t *testing.T) {
+	o := newClientOptions([]ClientOption{WithAuth("user", "secret"), WithDatabase(2), WithTLSConfig(nil),
+		WithReadTimeout(time.Second)})
+	so := o.sentinelOptions()
+	assert.Equal(t, "", so.username)
+	assert.Equal(t, "", so.password)
+	assert.Equal(t, 0, so.database)
+	assert.True(t, so.useTLS)
+	assert.Equal(t, time.Second, so.readTimeout)
+	// the options of the nodes are unchanged
+	assert.Equal(t, "secret", o.password)
+	assert.Equal(t, 2, o.database)
+
+	o = newClientOptions([]ClientOption{WithAuth("user", "secret"), WithSentinelAuth("sentinel", "other")})
+	so = o.sentinelOptions()
+	assert.Equal(t, "sentinel", so.username)
+	assert.Equal(t, "other", so.password)
+	assert.Equal(t, 2, len(so.dialOptions()))
+}
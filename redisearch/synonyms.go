@@ -0,0 +1,126 @@
+package redisearch
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ParseSynonyms parses synonym groups in the format of the synonyms.txt files of Solr, i.e.
+//
+//	# comment
+//	couch, sofa, divan
+//	tv, television => television
+//
+// Each line is a group of equivalent terms, identified by its first term; lines starting with
+// the same term are merged. As the synonym groups of RediSearch are symmetric, explicit mappings
+// (a, b => c) are loaded as groups of all their terms. Commas can be escaped with \,
+func ParseSynonyms(r io.Reader) (map[string][]string, error) {
+	groups := map[string][]string{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		sides := strings.Split(text, "=>")
+		if len(sides) > 2 {
+			return nil, fmt.Errorf("ParseSynonyms: more than one => on line %d", line)
+		}
+		var terms []string
+		for _, side := range sides {
+			terms = append(terms, splitSynonyms(side)...)
+		}
+		if len(terms) == 0 {
+			return nil, fmt.Errorf("ParseSynonyms: no terms on line %d", line)
+		}
+		groupId := terms[0]
+		groups[groupId] = appendMissing(groups[groupId], terms...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// splitSynonyms splits a comma separated list of terms, lower cased as by RediSearch
+func splitSynonyms(s string) []string {
+	var terms []string
+	var term []byte
+	add := func() {
+		if t := strings.TrimSpace(string(term)); t != "" {
+			terms = append(terms, strings.ToLower(t))
+		}
+		term = term[:0]
+	}
+	for ii := 0; ii < len(s); ii++ {
+		switch {
+		case s[ii] == '\\' && ii+1 < len(s):
+			ii++
+			term = append(term, s[ii])
+		case s[ii] == ',':
+			add()
+		default:
+			term = append(term, s[ii])
+		}
+	}
+	add()
+	return terms
+}
+
+// appendMissing appends the terms not in list yet
+func appendMissing(list []string, terms ...string) []string {
+	for _, term := range terms {
+		if sliceIndex(list, term) == -1 {
+			list = append(list, term)
+		}
+	}
+	return list
+}
+
+// SyncSynonyms updates the synonym groups of the index with the groups parsed from r with ParseSynonyms,
+// sending FT.SYNUPDATE only for the groups missing some of their terms. It returns the number of updated groups.
+// Synonyms cannot be removed from an index: terms deleted from the file stay in their groups
+// until the index is rebuilt, i.e. with Migrate
+func (i *Client) SyncSynonyms(r io.Reader, skipInitialScan bool) (updated int, err error) {
+	return i.SyncSynonymsContext(context.Background(), r, skipInitialScan)
+}
+
+// SyncSynonymsContext is the context-aware version of SyncSynonyms
+func (i *Client) SyncSynonymsContext(ctx context.Context, r io.Reader, skipInitialScan bool) (updated int, err error) {
+	groups, err := ParseSynonyms(r)
+	if err != nil {
+		return
+	}
+	current, err := i.SynDumpContext(ctx)
+	if err != nil {
+		return
+	}
+
+	groupIds := make([]string, 0, len(groups))
+	for groupId := range groups {
+		groupIds = append(groupIds, groupId)
+	}
+	sort.Strings(groupIds)
+	for _, groupId := range groupIds {
+		terms := groups[groupId]
+		synced := true
+		for _, term := range terms {
+			if sliceIndex(current[term], groupId) == -1 {
+				synced = false
+				break
+			}
+		}
+		if synced {
+			continue
+		}
+		if err = i.SynUpdateContext(ctx, groupId, skipInitialScan, terms...); err != nil {
+			return
+		}
+		updated++
+	}
+	return
+}
@@ -0,0 +1,67 @@
+package redisearch
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSynonyms(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		want    map[string][]string
+		wantErr bool
+	}{
+		{"empty", "# synonyms\n\n", map[string][]string{}, false},
+		{"equivalent", "Couch, sofa,divan\ntv, television\n",
+			map[string][]string{"couch": {"couch", "sofa", "divan"}, "tv": {"tv", "television"}}, false},
+		{"mapping", "tv, televisions => television", map[string][]string{"tv": {"tv", "televisions", "television"}}, false},
+		{"merged", "couch, sofa\ncouch, divan, sofa", map[string][]string{"couch": {"couch", "sofa", "divan"}}, false},
+		{"escaped", `1\,000, thousand`, map[string][]string{"1,000": {"1,000", "thousand"}}, false},
+		{"double-mapping", "a => b => c", nil, true},
+		{"no-terms", " , ", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSynonyms(strings.NewReader(tt.file))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSynonyms() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSynonyms() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_SynDumpReply(t *testing.T) {
+	conn := &scriptedConn{replies: []interface{}{[]interface{}{
+		[]byte("sofa"), []interface{}{[]byte("couch")},
+		[]byte("tv"), []interface{}{[]byte("tv"), []byte("screen")},
+	}}}
+	c := NewClientFromPool(&scriptedPool{conn: conn}, "idx")
+	groups, err := c.SynDump()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string][]string{"sofa": {"couch"}, "tv": {"tv", "screen"}}, groups)
+	assert.Equal(t, []string{"FT.SYNDUMP idx"}, conn.commands)
+}
+
+func TestClient_SyncSynonyms(t *testing.T) {
+	conn := &scriptedConn{replies: []interface{}{
+		[]interface{}{
+			[]byte("couch"), []interface{}{[]byte("couch")},
+			[]byte("sofa"), []interface{}{[]byte("couch")},
+			[]byte("tv"), []interface{}{[]byte("tv")},
+		},
+		"OK",
+	}}
+	c := NewClientFromPool(&scriptedPool{conn: conn}, "idx")
+	updated, err := c.SyncSynonyms(strings.NewReader("couch, sofa\ntv, television\n"), true)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, updated)
+	assert.Equal(t, []string{"FT.SYNDUMP idx", "FT.SYNUPDATE idx tv SKIPINITIALSCAN tv television"}, conn.commands)
+}
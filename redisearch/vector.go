@@ -0,0 +1,177 @@
+package redisearch
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// serialize returns the attributes of a vector field, validating them against the algorithm
+func (o VectorFieldOptions) serialize() (redis.Args, error) {
+	if o.Algorithm != VectorFlat && o.Algorithm != VectorHNSW {
+		return nil, fmt.Errorf("Invalid vector algorithm %q", o.Algorithm)
+	}
+	if o.Type == "" || o.Dim <= 0 || o.DistanceMetric == "" {
+		return nil, errors.New("Vector fields require a Type, a positive Dim and a DistanceMetric")
+	}
+	args := redis.Args{"TYPE", string(o.Type), "DIM", o.Dim, "DISTANCE_METRIC", string(o.DistanceMetric)}
+	if o.InitialCap > 0 {
+		args = append(args, "INITIAL_CAP", o.InitialCap)
+	}
+	if o.Algorithm == VectorFlat {
+		if o.M != 0 || o.EFConstruction != 0 || o.EFRuntime != 0 || o.Epsilon != 0 {
+			return nil, errors.New("M, EFConstruction, EFRuntime and Epsilon are HNSW options")
+		}
+		if o.BlockSize > 0 {
+			args = append(args, "BLOCK_SIZE", o.BlockSize)
+		}
+		return args, nil
+	}
+	if o.BlockSize != 0 {
+		return nil, errors.New("BlockSize is a FLAT option")
+	}
+	if o.M > 0 {
+		args = append(args, "M", o.M)
+	}
+	if o.EFConstruction > 0 {
+		args = append(args, "EF_CONSTRUCTION", o.EFConstruction)
+	}
+	if o.EFRuntime > 0 {
+		args = append(args, "EF_RUNTIME", o.EFRuntime)
+	}
+	if o.Epsilon > 0 {
+		args = append(args, "EPSILON", o.Epsilon)
+	}
+	return args, nil
+}
+
+// EncodeFloat32Vector encodes a vector in the binary format of FLOAT32 vector fields
+func EncodeFloat32Vector(v []float32) []byte {
+	b := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(f))
+	}
+	return b
+}
+
+// DecodeFloat32Vector decodes a vector encoded with EncodeFloat32Vector
+func DecodeFloat32Vector(b []byte) ([]float32, error) {
+	if len(b)%4 != 0 {
+		return nil, fmt.Errorf("Invalid FLOAT32 vector size: %d bytes", len(b))
+	}
+	v := make([]float32, len(b)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return v, nil
+}
+
+// EncodeFloat64Vector encodes a vector in the binary format of FLOAT64 vector fields
+func EncodeFloat64Vector(v []float64) []byte {
+	b := make([]byte, 8*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint64(b[8*i:], math.Float64bits(f))
+	}
+	return b
+}
+
+// DecodeFloat64Vector decodes a vector encoded with EncodeFloat64Vector
+func DecodeFloat64Vector(b []byte) ([]float64, error) {
+	if len(b)%8 != 0 {
+		return nil, fmt.Errorf("Invalid FLOAT64 vector size: %d bytes", len(b))
+	}
+	v := make([]float64, len(b)/8)
+	for i := range v {
+		v[i] = math.Float64frombits(binary.LittleEndian.Uint64(b[8*i:]))
+	}
+	return v, nil
+}
+
+// SetVector sets a FLOAT32 vector property, encoded with EncodeFloat32Vector
+func (d Document) SetVector(name string, v []float32) Document {
+	d.Properties[name] = EncodeFloat32Vector(v)
+	return d
+}
+
+// knnVectorParam is the name of the query parameter bound to the vector of a KNN clause
+const knnVectorParam = "KNN_VECTOR"
+
+// KNNClause is a K nearest neighbors clause, matching the K documents whose vectors
+// are closest to Vector among the documents matching the rest of the query
+type KNNClause struct {
+	K     int
+	Field string
+
+	// Vector is the encoded query vector, see EncodeFloat32Vector
+	Vector []byte
+
+	// EFRuntime overrides the EF_RUNTIME of HNSW fields when set
+	EFRuntime int
+
+	// ScoreField is the name the distance is returned with, __<Field>_score by default
+	ScoreField string
+}
+
+// NewKNNClause creates a new KNN clause, matching the k documents whose vector field is closest to vector
+func NewKNNClause(k int, field string, vector []byte) *KNNClause {
+	return &KNNClause{K: k, Field: field, Vector: vector}
+}
+
+// SetEFRuntime sets the number of candidates considered by the query on HNSW fields
+func (c *KNNClause) SetEFRuntime(efRuntime int) *KNNClause {
+	c.EFRuntime = efRuntime
+	return c
+}
+
+// SetScoreField sets the name the distance is returned with
+func (c *KNNClause) SetScoreField(field string) *KNNClause {
+	c.ScoreField = field
+	return c
+}
+
+// scoreField returns the name the distance is returned with
+func (c *KNNClause) scoreField() string {
+	if c.ScoreField != "" {
+		return c.ScoreField
+	}
+	return "__" + c.Field + "_score"
+}
+
+// queryExpr applies the clause to the query string raw, as in (raw)=>[KNN 10 @vec $KNN_VECTOR AS score]
+func (c *KNNClause) queryExpr(raw string) string {
+	if raw = strings.TrimSpace(raw); raw == "" || raw == "*" {
+		raw = "*"
+	} else {
+		raw = "(" + raw + ")"
+	}
+	expr := raw + "=>[KNN " + strconv.Itoa(c.K) + " @" + c.Field + " $" + knnVectorParam
+	if c.EFRuntime > 0 {
+		expr += " EF_RUNTIME " + strconv.Itoa(c.EFRuntime)
+	}
+	return expr + " AS " + c.scoreField() + "]"
+}
+
+// SetKNN adds a KNN vector similarity clause to the query: the documents matching the query string
+// are ranked by their distance from the vector, returned in Document.Distance.
+// The results are not sorted by distance unless sorted by the score field of the clause, i.e.
+//
+//	knn := redisearch.NewKNNClause(10, "embedding", redisearch.EncodeFloat32Vector(v))
+//	q := redisearch.NewQuery("@category:{books}").SetKNN(knn).SetSortBy("__embedding_score", true)
+func (q *Query) SetKNN(c *KNNClause) *Query {
+	q.KNN = c
+	return q
+}
+
+// loadDistance parses the distance of a document returned by a KNN query
+func (c *KNNClause) loadDistance(doc *Document) {
+	if s, ok := doc.Properties[c.scoreField()].(string); ok {
+		if distance, err := strconv.ParseFloat(s, 64); err == nil {
+			doc.Distance = distance
+		}
+	}
+}
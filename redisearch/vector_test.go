@@ -0,0 +1,48 @@
+package redisearch
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestEncodeFloat32Vector(t *testing.T) {
+	v := []float32{0, 1, -2.5, float32(math.Inf(1))}
+	b := EncodeFloat32Vector(v)
+	if want := []byte{0, 0, 0, 0, 0, 0, 0x80, 0x3f, 0, 0, 0x20, 0xc0, 0, 0, 0x80, 0x7f}; !reflect.DeepEqual(b, want) {
+		t.Errorf("EncodeFloat32Vector() = %v, want %v", b, want)
+	}
+	got, err := DecodeFloat32Vector(b)
+	if err != nil || !reflect.DeepEqual(got, v) {
+		t.Errorf("DecodeFloat32Vector() = %v, %v, want %v", got, err, v)
+	}
+	if _, err := DecodeFloat32Vector(b[:3]); err == nil {
+		t.Errorf("DecodeFloat32Vector() expected an error for a truncated vector")
+	}
+}
+
+func TestEncodeFloat64Vector(t *testing.T) {
+	v := []float64{0, 1, -2.5}
+	b := EncodeFloat64Vector(v)
+	if len(b) != 24 {
+		t.Fatalf("EncodeFloat64Vector() size = %d, want 24", len(b))
+	}
+	got, err := DecodeFloat64Vector(b)
+	if err != nil || !reflect.DeepEqual(got, v) {
+		t.Errorf("DecodeFloat64Vector() = %v, %v, want %v", got, err, v)
+	}
+	if _, err := DecodeFloat64Vector(b[:7]); err == nil {
+		t.Errorf("DecodeFloat64Vector() expected an error for a truncated vector")
+	}
+}
+
+func TestKNNClause_loadDistance(t *testing.T) {
+	doc := NewDocument("doc1", 1).Set("__vec_score", "0.25").SetVector("vec", []float32{1, 2})
+	NewKNNClause(10, "vec", nil).loadDistance(&doc)
+	if doc.Distance != 0.25 {
+		t.Errorf("loadDistance() = %v, want %v", doc.Distance, 0.25)
+	}
+	if _, ok := doc.Properties["vec"].([]byte); !ok {
+		t.Errorf("SetVector() expected an encoded vector, got %T", doc.Properties["vec"])
+	}
+}